@@ -52,3 +52,25 @@ const Password = "password"
 
 //vault
 const TokenFilePath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+const ContextStepPath = "contextStepPath"
+const ContextDryRun = "contextDryRun"
+const ContextExecutionPlan = "contextExecutionPlan"
+const ContextCheckpointStore = "contextCheckpointStore"
+const ContextExecutionReport = "contextExecutionReport"
+const ContextGoContext = "contextGoContext"
+const ContextStepInterceptors = "contextStepInterceptors"
+const ContextEventEmitter = "contextEventEmitter"
+const ContextRetentionPolicies = "contextRetentionPolicies"
+const ContextRollbackTracking = "contextRollbackTracking"
+
+// CleanupFinalizer protects the CR until the cleanup on its deletion is done
+const CleanupFinalizer = "netcracker.com/nosqldb-operator-cleanup"
+
+// PauseAnnotation set to "true" on the CR freezes its reconciliation,
+// the controller has to watch the annotation changes, see core.ReconcilePredicate
+const PauseAnnotation = "netcracker.com/reconcile-paused"
+
+// ForceReconcileAnnotation with any value on the CR triggers the full reconcile, it is removed after the success.
+// The controller has to watch the annotation changes, see core.ReconcilePredicate
+const ForceReconcileAnnotation = "netcracker.com/force-reconcile"
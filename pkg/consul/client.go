@@ -1,6 +1,7 @@
 package consul
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -8,8 +9,11 @@ import (
 	"time"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/tracing"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	consulApi "github.com/hashicorp/consul/api"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"k8s.io/client-go/rest"
 )
@@ -29,11 +33,18 @@ type ConsulClientImpl struct {
 	logger             *zap.Logger
 	consulClient       *consulApi.Client
 	aclToken           string
+	ctx                context.Context
 }
 
 func NewConsulClientImpl(nodeIP string, namespace string, registration *types.ConsulRegistration, kubeConfig *rest.Config, logger *zap.Logger) (ConsulClient, error) {
+	return NewConsulClientImplWithContext(context.Background(), nodeIP, namespace, registration, kubeConfig, logger)
+}
+
+// NewConsulClientImplWithContext creates the client which requests are cancelled with the context
+func NewConsulClientImplWithContext(ctx context.Context, nodeIP string, namespace string, registration *types.ConsulRegistration, kubeConfig *rest.Config, logger *zap.Logger) (ConsulClient, error) {
 	if registration != nil && registration.Enabled {
 		client := ConsulClientImpl{
+			ctx:                ctx,
 			nodeIP:             nodeIP,
 			namespace:          namespace,
 			consulRegistration: registration,
@@ -53,8 +64,13 @@ func NewConsulClientImpl(nodeIP string, namespace string, registration *types.Co
 	}
 }
 
-func (c *ConsulClientImpl) Register(serviceRegistration *consulApi.AgentServiceRegistration) error {
-	err := c.consulClient.Agent().ServiceRegisterOpts(serviceRegistration, consulApi.ServiceRegisterOpts{ReplaceExistingChecks: true})
+func (c *ConsulClientImpl) Register(serviceRegistration *consulApi.AgentServiceRegistration) (err error) {
+	ctx, finish := c.startCall("Register", serviceRegistration.ID)
+	defer func() {
+		finish(err)
+	}()
+
+	err = c.consulClient.Agent().ServiceRegisterOpts(serviceRegistration, consulApi.ServiceRegisterOpts{ReplaceExistingChecks: true}.WithContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -62,8 +78,16 @@ func (c *ConsulClientImpl) Register(serviceRegistration *consulApi.AgentServiceR
 	return nil
 }
 
-func (c *ConsulClientImpl) Maintenance(serviceID string, isMainteaning bool, reason ...string) error {
-	var err error
+func (c *ConsulClientImpl) Maintenance(serviceID string, isMainteaning bool, reason ...string) (err error) {
+	ctx, finish := c.startCall("Maintenance", serviceID)
+	defer func() {
+		finish(err)
+	}()
+
+	// consul client doesn't accept context for maintenance requests
+	if err = ctx.Err(); err != nil {
+		return err
+	}
 
 	if isMainteaning {
 		err = c.consulClient.Agent().EnableServiceMaintenance(serviceID, strings.Join(reason, ". "))
@@ -77,23 +101,46 @@ func (c *ConsulClientImpl) Maintenance(serviceID string, isMainteaning bool, rea
 	return nil
 }
 
-func (c *ConsulClientImpl) Deregister(serviceID string) error {
-	if err := c.consulClient.Agent().ServiceDeregister(serviceID); err != nil {
+func (c *ConsulClientImpl) Deregister(serviceID string) (err error) {
+	ctx, finish := c.startCall("Deregister", serviceID)
+	defer func() {
+		finish(err)
+	}()
+
+	if err = c.consulClient.Agent().ServiceDeregisterOpts(serviceID, (&consulApi.QueryOptions{}).WithContext(ctx)); err != nil {
 		return err
 	}
 	c.logger.Debug(fmt.Sprintf("Service with ID '%s' has been deregistred", serviceID))
 	return nil
 }
 
-func (c *ConsulClientImpl) Logout() error {
-	var err error
+func (c *ConsulClientImpl) Logout() (err error) {
+	ctx, finish := c.startCall("Logout", "")
+	defer func() {
+		finish(err)
+	}()
+
 	if c.aclToken != "" {
-		_, err = c.consulClient.ACL().Logout(&consulApi.WriteOptions{Token: c.aclToken})
+		_, err = c.consulClient.ACL().Logout((&consulApi.WriteOptions{Token: c.aclToken}).WithContext(ctx))
 	}
 	c.logger.Debug("Consul client is logged-out")
 	return err
 }
 
+// startCall starts the span of the Consul call, the returned function ends it and records the call metrics
+func (c *ConsulClientImpl) startCall(operation string, serviceID string) (context.Context, func(err error)) {
+	var attrs []attribute.KeyValue
+	if serviceID != "" {
+		attrs = append(attrs, attribute.String("consul.service.id", serviceID))
+	}
+	start := time.Now()
+	ctx, span := tracing.StartSpan(c.ctx, "Consul "+operation, attrs...)
+	return ctx, func(err error) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveExternalCall(metrics.SystemConsul, operation, start, err)
+	}
+}
+
 // Check Impl implements interface
 var _ ConsulClient = &ConsulClientImpl{}
 
@@ -141,13 +188,17 @@ func (c *ConsulClientImpl) aclClient(consulConfig *consulApi.Config) (*consulApi
 		return client, err
 	}
 
-	aclToken, _, tokenErr := client.ACL().Login(loginParams, &consulApi.WriteOptions{})
+	aclToken, _, tokenErr := client.ACL().Login(loginParams, (&consulApi.WriteOptions{}).WithContext(c.ctx))
 	if tokenErr != nil {
 		return nil, tokenErr
 	}
 	consulConfig.Token = aclToken.SecretID
 	c.aclToken = aclToken.SecretID
-	time.Sleep(1 * time.Second)
+	select {
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	case <-time.After(time.Second):
+	}
 
 	return consulApi.NewClient(consulConfig)
 }
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcilePredicate filters the CR events of the controller running ReconcileCommonService.
+// PauseAnnotation and ForceReconcileAnnotation take effect only when their update reaches Reconcile,
+// so the generation filter has to let the annotation changes through as well, e.g.
+//
+//	ctrl.NewControllerManagedBy(mgr).For(&v1.MongoDBService{}, builder.WithPredicates(core.ReconcilePredicate())).Complete(r)
+func ReconcilePredicate() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
+// isPaused reports whether the reconciliation of the CR is frozen with PauseAnnotation
+func isPaused(instance client.Object) bool {
+	paused, _ := strconv.ParseBool(instance.GetAnnotations()[constants.PauseAnnotation])
+	return paused
+}
+
+// isForceReconcileRequested reports whether the full reconcile of the CR is requested with ForceReconcileAnnotation
+func isForceReconcileRequested(instance client.Object) bool {
+	return instance.GetAnnotations()[constants.ForceReconcileAnnotation] != ""
+}
+
+// setPaused stores the paused state of the CR and reports whether it has changed
+func (r *ReconcileCommonService) setPaused(request reconcile.Request, paused bool) bool {
+	r.pausedMutex.Lock()
+	defer r.pausedMutex.Unlock()
+	if r.paused == nil {
+		r.paused = make(map[string]bool)
+	}
+	if r.paused[request.String()] == paused {
+		return false
+	}
+	if paused {
+		r.paused[request.String()] = true
+	} else {
+		delete(r.paused, request.String())
+	}
+	return true
+}
+
+// isPausedConditionStale reports whether the Paused condition doesn't match the annotation, e.g. after the operator restart
+func (r *ReconcileCommonService) isPausedConditionStale(paused bool) bool {
+	reconciler, ok := r.Reconciler.(ConditionsReconciler)
+	if !ok || reconciler.GetConditions() == nil {
+		return false
+	}
+	condition := meta.FindStatusCondition(*reconciler.GetConditions(), ConditionPaused)
+	if condition == nil {
+		return paused
+	}
+	return (condition.Status == metav1.ConditionTrue) != paused
+}
+
+// commitPausedStatus reports the pause transition with the event and the Paused condition
+func (r *ReconcileCommonService) commitPausedStatus(crHandler CRStatusHandler, ctx ExecutionContext, paused bool, logger *zap.Logger) {
+	condition := metav1.Condition{Type: ConditionPaused}
+	if paused {
+		condition.Status, condition.Reason = metav1.ConditionTrue, "ReconcilePaused"
+		condition.Message = fmt.Sprintf("Reconcile is paused with %s annotation", constants.PauseAnnotation)
+		EmitNormalEvent(ctx, EventReasonPaused, condition.Message)
+	} else {
+		condition.Status, condition.Reason = metav1.ConditionFalse, "ReconcileResumed"
+		condition.Message = "Reconcile is resumed"
+		EmitNormalEvent(ctx, EventReasonResumed, condition.Message)
+	}
+	if _, ok := r.Reconciler.(ConditionsReconciler); !ok {
+		return
+	}
+	if err := crHandler.SetConditions(condition).Commit(); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to update paused condition, err: %v", err))
+	}
+}
+
+// acknowledgeForceReconcile removes ForceReconcileAnnotation from the CR after the forced reconcile succeeds
+func (r *ReconcileCommonService) acknowledgeForceReconcile(ctx ExecutionContext, instance client.Object) error {
+	patch := client.MergeFrom(instance.DeepCopyObject().(client.Object))
+	annotations := instance.GetAnnotations()
+	delete(annotations, constants.ForceReconcileAnnotation)
+	instance.SetAnnotations(annotations)
+	return r.Client.Patch(GetContext(ctx), instance, patch)
+}
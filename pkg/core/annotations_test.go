@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAnnotations(t *testing.T) {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "namespace", Name: "cr"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "cr", Annotations: map[string]string{
+		constants.PauseAnnotation:          "true",
+		constants.ForceReconcileAnnotation: "2024-07-01T10:00:00Z",
+	}}}
+	kubeClient := fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+	instance := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), request.NamespacedName, instance))
+
+	reconciler := &testConditionsReconciler{instance: instance}
+	r := &ReconcileCommonService{Client: kubeClient, Reconciler: reconciler}
+	ctx := NewInitExecutionContext(map[string]interface{}{})
+	crHandler := DefaultCRStatusHandler{Reconciler: reconciler, KubeClient: kubeClient}
+
+	t.Run("Pause", func(t *testing.T) {
+		assert.True(t, isPaused(instance))
+		assert.True(t, r.isPausedConditionStale(true))
+		assert.True(t, r.setPaused(request, true))
+		assert.False(t, r.setPaused(request, true))
+
+		r.commitPausedStatus(crHandler, ctx, true, GetLogger(true))
+		assert.True(t, meta.IsStatusConditionTrue(reconciler.conditions, ConditionPaused))
+		assert.False(t, r.isPausedConditionStale(true))
+
+		assert.True(t, r.setPaused(request, false))
+		assert.True(t, r.isPausedConditionStale(false))
+		r.commitPausedStatus(crHandler, ctx, false, GetLogger(true))
+		assert.True(t, meta.IsStatusConditionFalse(reconciler.conditions, ConditionPaused))
+	})
+
+	t.Run("Force reconcile is acknowledged", func(t *testing.T) {
+		assert.True(t, isForceReconcileRequested(instance))
+		assert.Nil(t, r.acknowledgeForceReconcile(ctx, instance))
+
+		stored := &v1.Pod{}
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+		assert.False(t, isForceReconcileRequested(stored))
+		assert.Equal(t, "true", stored.Annotations[constants.PauseAnnotation])
+	})
+}
+
+func TestAnnotationsThroughPredicate(t *testing.T) {
+	executions := 0
+	r, kubeClient := newTestReconcileService(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		executions++
+		return nil
+	}})
+	filter := ReconcilePredicate()
+	// reconcileOnUpdate changes the CR and reconciles it if the update event passes the predicate
+	reconcileOnUpdate := func(change func(cr *v1.Pod)) bool {
+		old := getTestCR(t, kubeClient)
+		cr := old.DeepCopy()
+		change(cr)
+		assert.Nil(t, kubeClient.Update(context.Background(), cr))
+		updated := event.UpdateEvent{ObjectOld: old, ObjectNew: getTestCR(t, kubeClient)}
+		// the annotations don't change the generation, so they are filtered out without the annotation predicate
+		assert.False(t, predicate.GenerationChangedPredicate{}.Update(updated))
+		if !filter.Update(updated) {
+			return false
+		}
+		_, err := r.Reconcile(context.Background(), testRequest)
+		assert.Nil(t, err)
+		return true
+	}
+
+	_, err := r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, executions)
+	conditions := r.Reconciler.(ConditionsReconciler).GetConditions()
+
+	assert.True(t, reconcileOnUpdate(func(cr *v1.Pod) {
+		cr.Annotations = map[string]string{constants.PauseAnnotation: "true"}
+	}))
+	assert.True(t, meta.IsStatusConditionTrue(*conditions, ConditionPaused))
+
+	assert.True(t, reconcileOnUpdate(func(cr *v1.Pod) {
+		cr.Annotations = map[string]string{constants.ForceReconcileAnnotation: "2024-07-01T10:00:00Z"}
+	}))
+	assert.True(t, meta.IsStatusConditionFalse(*conditions, ConditionPaused))
+	assert.Equal(t, 2, executions)
+	assert.False(t, isForceReconcileRequested(getTestCR(t, kubeClient)))
+
+	// the other metadata changes are still filtered out
+	assert.False(t, reconcileOnUpdate(func(cr *v1.Pod) {
+		cr.Labels = map[string]string{"app": "db"}
+	}))
+	assert.Equal(t, 2, executions)
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetContext returns the context of the current reconcile which is cancelled on operator shutdown
+// or when the pipeline or step deadline is exceeded. Background context is returned if it's not set.
+func GetContext(ctx ExecutionContext) context.Context {
+	if goCtx, ok := GoContextKey.Lookup(ctx); ok && goCtx != nil {
+		return goCtx
+	}
+	return context.Background()
+}
+
+// checkCancelled returns an error if the step must not be started because the context is done
+func checkCancelled(ctx ExecutionContext) error {
+	if err := GetContext(ctx).Err(); err != nil {
+		return &ExecutionError{Msg: fmt.Sprintf("Step %s is aborted: %v", GetStepPath(ctx), err), Err: err}
+	}
+	return nil
+}
+
+// sleepWithContext waits for the duration or until the context is done
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// TimeoutStep is a decorator which limits execution time of the wrapped step.
+// The deadline is applied through GetContext, so the step has to use it for its waits and calls.
+type TimeoutStep struct {
+	Executable
+	Timeout time.Duration
+}
+
+func WithTimeout(step Executable, timeout time.Duration) *TimeoutStep {
+	return &TimeoutStep{Executable: step, Timeout: timeout}
+}
+
+func (r *TimeoutStep) Execute(ctx ExecutionContext) error {
+	goCtx, cancel := context.WithTimeout(GetContext(ctx), r.Timeout)
+	defer cancel()
+
+	err := r.Executable.Execute(withValue(ctx, GoContextKey.Name(), goCtx))
+	if err != nil && errors.Is(goCtx.Err(), context.DeadlineExceeded) {
+		return &ExecutionError{Msg: fmt.Sprintf("Step %s is not completed in %v: %v", GetStepName(r), r.Timeout, err), Err: err}
+	}
+	return err
+}
+
+func (r *TimeoutStep) Unwrap() Executable {
+	return r.Executable
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const CheckpointConfigMapSuffix = "-checkpoints"
+
+// CheckpointStore persists completion markers of the steps, so a failed reconcile can be resumed from the failed step
+type CheckpointStore interface {
+	IsCompleted(stepPath string) bool
+	MarkCompleted(stepPath string) error
+	HasProgress() bool
+	Clear() error
+}
+
+// ConfigMapCheckpointStore keeps completion markers in a config map.
+// A marker is valid only for the spec hash it has been stored with, so any spec change leads to a full run.
+type ConfigMapCheckpointStore struct {
+	Client    client.Client
+	Name      string
+	Namespace string
+	SpecHash  string
+	markers   map[string]string
+	mutex     sync.Mutex
+}
+
+var _ CheckpointStore = &ConfigMapCheckpointStore{}
+
+func NewConfigMapCheckpointStore(kubeClient client.Client, name string, namespace string, specHash string) (*ConfigMapCheckpointStore, error) {
+	store := &ConfigMapCheckpointStore{
+		Client:    kubeClient,
+		Name:      name,
+		Namespace: namespace,
+		SpecHash:  specHash,
+		markers:   map[string]string{},
+	}
+
+	cm := &v1.ConfigMap{}
+	err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	for key, value := range cm.Data {
+		store.markers[key] = value
+	}
+	return store, nil
+}
+
+func (r *ConfigMapCheckpointStore) IsCompleted(stepPath string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.markers[checkpointKey(stepPath)] == r.SpecHash
+}
+
+func (r *ConfigMapCheckpointStore) MarkCompleted(stepPath string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.markers[checkpointKey(stepPath)] = r.SpecHash
+
+	cm := &v1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: r.Name, Namespace: r.Namespace}, cm)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: v12.ObjectMeta{Name: r.Name, Namespace: r.Namespace},
+			Data:       r.copyMarkers(),
+		}
+		return r.Client.Create(context.TODO(), cm)
+	}
+
+	cm.Data = r.copyMarkers()
+	return r.Client.Update(context.TODO(), cm)
+}
+
+func (r *ConfigMapCheckpointStore) HasProgress() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, hash := range r.markers {
+		if hash == r.SpecHash {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConfigMapCheckpointStore) Clear() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.markers = map[string]string{}
+	return DeleteRuntimeObject(r.Client, &v1.ConfigMap{
+		ObjectMeta: v12.ObjectMeta{Name: r.Name, Namespace: r.Namespace},
+	})
+}
+
+func (r *ConfigMapCheckpointStore) copyMarkers() map[string]string {
+	data := make(map[string]string, len(r.markers))
+	for key, value := range r.markers {
+		data[key] = value
+	}
+	return data
+}
+
+// checkpointKey converts the step path to a valid config map key
+func checkpointKey(stepPath string) string {
+	return strings.NewReplacer(StepPathSeparator, ".", "#", "_").Replace(stepPath)
+}
+
+func GetCheckpointStore(ctx ExecutionContext) CheckpointStore {
+	return CheckpointStoreKey.Get(ctx)
+}
+
+// isResumable reports whether the step may be skipped on resume.
+// Context variables of the skipped step would be missing for the next steps, so only the steps
+// declaring with DependentExecutable that they produce nothing are skipped. Compounds are always traversed.
+func isResumable(element Executable) bool {
+	step := UnwrapStep(element)
+	if _, ok := step.(ExecutableCompound); ok {
+		return false
+	}
+	dependent, ok := step.(DependentExecutable)
+	return ok && len(dependent.Produces()) == 0
+}
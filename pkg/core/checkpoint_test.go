@@ -0,0 +1,64 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().Build()
+	var executed []string
+	failLast := true
+	step := func(name string) *testStep {
+		return &testStep{executeFunc: func(ctx ExecutionContext) error {
+			executed = append(executed, name)
+			if name == "undeclared" {
+				ctx.Set("nodes", []string{"node-1"})
+			}
+			if name == "last" && ctx.Get("nodes") == nil {
+				return errors.New("nodes are not set")
+			}
+			if name == "last" && failLast {
+				return errors.New("last step failed")
+			}
+			return nil
+		}}
+	}
+	// declared steps without produced variables are skipped on resume
+	declared := func(name string, produces ...string) *dependentTestStep {
+		return &dependentTestStep{testStep: *step(name), produces: produces}
+	}
+
+	compound := &DefaultCompound{}
+	compound.AddStep(declared("first"))
+	compound.AddStep(declared("producer", "pvcNames"))
+	compound.AddStep(step("undeclared"))
+	compound.AddStep(declared("second"))
+	compound.AddStep(declared("last"))
+
+	run := func() error {
+		store, err := NewConfigMapCheckpointStore(kubeClient, "hash"+CheckpointConfigMapSuffix, "namespace", "spec-hash")
+		assert.Nil(t, err)
+		executor := DefaultExecutor()
+		executor.SetExecutable(compound)
+		return executor.Execute(NewInitExecutionContext(map[string]interface{}{
+			constants.ContextCheckpointStore: store,
+		}))
+	}
+
+	assert.NotNil(t, run())
+	assert.Equal(t, []string{"first", "producer", "undeclared", "second", "last"}, executed)
+
+	executed = nil
+	failLast = false
+	assert.Nil(t, run())
+	assert.Equal(t, []string{"producer", "undeclared", "last"}, executed)
+
+	store, _ := NewConfigMapCheckpointStore(kubeClient, "hash"+CheckpointConfigMapSuffix, "namespace", "other-spec-hash")
+	assert.False(t, store.HasProgress())
+	assert.False(t, store.IsCompleted("DefaultCompound/dependentTestStep"))
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ResourceKind is a kind of external or cluster resources created for the CR which are removed on its deletion
+type ResourceKind string
+
+const (
+	// ConsulRegistrationsResource is the services registered in Consul
+	ConsulRegistrationsResource ResourceKind = "ConsulRegistrations"
+	// ConsulProxyServicesResource is the proxy services of Consul checks labelled with consul-check-proxy set to the consul settings names of the CR
+	ConsulProxyServicesResource ResourceKind = "ConsulProxyServices"
+	// VaultSecretsResource is the secrets written to Vault by MoveSecretToVault
+	VaultSecretsResource ResourceKind = "VaultSecrets"
+	// VaultDBEnginesResource is the database configs and static roles of the Vault DB engine
+	VaultDBEnginesResource ResourceKind = "VaultDBEngines"
+	// SpecConfigMapResource is the config map with the spec hashes
+	SpecConfigMapResource ResourceKind = "SpecConfigMap"
+	// CheckpointsConfigMapResource is the config map with the checkpoints of the failed reconcile
+	CheckpointsConfigMapResource ResourceKind = "CheckpointsConfigMap"
+)
+
+type RetentionPolicy string
+
+const (
+	RetentionDelete RetentionPolicy = "Delete"
+	RetentionRetain RetentionPolicy = "Retain"
+)
+
+// RetentionPolicies defines what happens to the resources of each kind on the CR deletion.
+// Resources of the kinds which are not listed are deleted.
+type RetentionPolicies map[ResourceKind]RetentionPolicy
+
+func (p RetentionPolicies) Policy(kind ResourceKind) RetentionPolicy {
+	if policy, ok := p[kind]; ok && policy != "" {
+		return policy
+	}
+	return RetentionDelete
+}
+
+func (p RetentionPolicies) Retains(kind ResourceKind) bool {
+	return p.Policy(kind) == RetentionRetain
+}
+
+// ShouldDelete reports whether the cleanup step has to remove the resources of the kind
+func ShouldDelete(ctx ExecutionContext, kind ResourceKind) bool {
+	return !RetentionPoliciesKey.Get(ctx).Retains(kind)
+}
+
+func (r *ReconcileCommonService) finalizer() string {
+	if r.Finalizer != "" {
+		return r.Finalizer
+	}
+	return constants.CleanupFinalizer
+}
+
+// ensureFinalizer adds the finalizer to the CR if the cleanup is configured
+func (r *ReconcileCommonService) ensureFinalizer(ctx ExecutionContext, instance client.Object) error {
+	if r.CleanupBuilder == nil || controllerutil.ContainsFinalizer(instance, r.finalizer()) {
+		return nil
+	}
+	controllerutil.AddFinalizer(instance, r.finalizer())
+	return r.Client.Update(GetContext(ctx), instance)
+}
+
+// cleanup runs CleanupBuilder on the CR deletion and releases the CR by removing the finalizer.
+// The finalizer is kept if the cleanup fails, so the cleanup is retried on the next reconcile.
+func (r *ReconcileCommonService) cleanup(ctx ExecutionContext, instance client.Object, logger *zap.Logger) error {
+	if !controllerutil.ContainsFinalizer(instance, r.finalizer()) {
+		logger.Debug("CR is being deleted and has no cleanup finalizer, nothing to do")
+		return nil
+	}
+
+	logger.Info("CR is being deleted, performing cleanup...")
+	EmitNormalEvent(ctx, EventReasonCleanupStarted, "Cleanup of the CR resources started")
+	ServiceDeployTypeKey.Set(ctx, Uninstall)
+	RetentionPoliciesKey.Set(ctx, r.Retention)
+
+	if r.CleanupBuilder != nil {
+		r.Executor.SetExecutable(r.CleanupBuilder.Build(ctx))
+		if err := r.Executor.Execute(ctx); err != nil {
+			EmitWarningEvent(ctx, EventReasonCleanupFailed, fmt.Sprintf("Cleanup failed: %v", err))
+			return err
+		}
+	}
+	if DryRunKey.Get(ctx) {
+		return nil
+	}
+
+	if r.Retention.Retains(SpecConfigMapResource) {
+		logger.Info(fmt.Sprintf("Config map %s is retained", HashConfigMapKey.Get(ctx)))
+	} else if err := DeleteSpecConfigMap(ctx); err != nil {
+		EmitWarningEvent(ctx, EventReasonCleanupFailed, fmt.Sprintf("Failed to delete spec config map: %v", err))
+		return fmt.Errorf("Failed to delete spec config map, err: %w", err)
+	}
+
+	checkpointsConfigMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: RequestKey.MustGet(ctx).Namespace,
+		Name:      HashConfigMapKey.Get(ctx) + CheckpointConfigMapSuffix,
+	}}
+	if r.Retention.Retains(CheckpointsConfigMapResource) {
+		logger.Info(fmt.Sprintf("Config map %s is retained", checkpointsConfigMap.Name))
+	} else if err := DeleteRuntimeObjectContext(GetContext(ctx), r.Client, checkpointsConfigMap); err != nil {
+		EmitWarningEvent(ctx, EventReasonCleanupFailed, fmt.Sprintf("Failed to delete checkpoints config map: %v", err))
+		return fmt.Errorf("Failed to delete checkpoints config map, err: %w", err)
+	}
+
+	EmitNormalEvent(ctx, EventReasonCleanedUp, "Cleanup of the CR resources finished")
+	controllerutil.RemoveFinalizer(instance, r.finalizer())
+	if err := r.Client.Update(GetContext(ctx), instance); err != nil {
+		return fmt.Errorf("Failed to remove finalizer %s, err: %w", r.finalizer(), err)
+	}
+	logger.Info("Cleanup is finished, finalizer is removed")
+	return nil
+}
+
+// isPersisted reports whether the instance is read from the cluster, e.g. it is not found on deletion
+func isPersisted(instance client.Object) bool {
+	if instance == nil {
+		return false
+	}
+	if value := reflect.ValueOf(instance); value.Kind() == reflect.Ptr && value.IsNil() {
+		return false
+	}
+	return instance.GetResourceVersion() != ""
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type testBuilder struct {
+	step Executable
+}
+
+func (r *testBuilder) Build(ctx ExecutionContext) Executable {
+	return r.step
+}
+
+func TestCleanup(t *testing.T) {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "namespace", Name: "cr"}}
+	hashConfigMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "hash"}}
+	checkpointsConfigMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "hash" + CheckpointConfigMapSuffix}}
+
+	run := func(t *testing.T, retention RetentionPolicies, cleanupErr error) (client.Client, *v1.ConfigMap, error) {
+		cr := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "cr"}}
+		kubeClient := fake.NewClientBuilder().WithObjects(cr, hashConfigMap.DeepCopy(), checkpointsConfigMap.DeepCopy()).Build()
+		var deployType MicroServiceDeployType
+		var deleteSecrets bool
+		r := &ReconcileCommonService{
+			Client:   kubeClient,
+			Executor: DefaultExecutor(),
+			CleanupBuilder: &testBuilder{step: &testStep{executeFunc: func(ctx ExecutionContext) error {
+				deployType = ServiceDeployTypeKey.Get(ctx)
+				deleteSecrets = ShouldDelete(ctx, VaultSecretsResource)
+				return cleanupErr
+			}}},
+			Retention: retention,
+		}
+		ctx := NewInitExecutionContext(map[string]interface{}{
+			constants.ContextRequest:       request,
+			constants.ContextClient:        kubeClient,
+			constants.ContextHashConfigMap: "hash",
+		})
+		logger := GetLogger(true)
+
+		assert.Nil(t, kubeClient.Get(context.Background(), request.NamespacedName, cr))
+		assert.Nil(t, r.ensureFinalizer(ctx, cr))
+		assert.True(t, controllerutil.ContainsFinalizer(cr, constants.CleanupFinalizer))
+
+		assert.Nil(t, kubeClient.Delete(context.Background(), cr))
+		assert.Nil(t, kubeClient.Get(context.Background(), request.NamespacedName, cr))
+		assert.False(t, cr.GetDeletionTimestamp().IsZero())
+
+		err := r.cleanup(ctx, cr, logger)
+		assert.Equal(t, Uninstall, deployType)
+		assert.Equal(t, !retention.Retains(VaultSecretsResource), deleteSecrets)
+		return kubeClient, cr, err
+	}
+
+	t.Run("Finalizer is removed after cleanup", func(t *testing.T) {
+		kubeClient, cr, err := run(t, nil, nil)
+		assert.Nil(t, err)
+		assert.NotNil(t, kubeClient.Get(context.Background(), request.NamespacedName, cr))
+		assert.NotNil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(hashConfigMap), &v1.ConfigMap{}))
+		assert.NotNil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(checkpointsConfigMap), &v1.ConfigMap{}))
+	})
+
+	t.Run("Retained resources are kept", func(t *testing.T) {
+		kubeClient, _, err := run(t, RetentionPolicies{VaultSecretsResource: RetentionRetain, SpecConfigMapResource: RetentionRetain,
+			CheckpointsConfigMapResource: RetentionRetain}, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(hashConfigMap), &v1.ConfigMap{}))
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(checkpointsConfigMap), &v1.ConfigMap{}))
+	})
+
+	t.Run("Finalizer is kept if cleanup fails", func(t *testing.T) {
+		kubeClient, cr, err := run(t, nil, errors.New("vault is unavailable"))
+		assert.NotNil(t, err)
+		assert.Nil(t, kubeClient.Get(context.Background(), request.NamespacedName, cr))
+		assert.True(t, controllerutil.ContainsFinalizer(cr, constants.CleanupFinalizer))
+	})
+}
@@ -5,20 +5,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/Netcracker/qubership-credential-manager/pkg/informer"
 	"github.com/Netcracker/qubership-credential-manager/pkg/manager"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/consul"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/tracing"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/vault"
+	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -41,6 +46,38 @@ type CommonReconciler interface {
 	UpdatePassWithFullReconcile() bool
 }
 
+// RollbackStatusReconciler is an optional extension of CommonReconciler
+// which stores the result of failed steps compensation in the CR status
+type RollbackStatusReconciler interface {
+	UpdateRollbackStatus(status types.RollbackStatus)
+}
+
+// ExecutionReportReconciler is an optional extension of CommonReconciler
+// which stores the per-step execution report in the CR status
+type ExecutionReportReconciler interface {
+	UpdateExecutionReport(report types.ExecutionReport)
+}
+
+// RequeueStatusReconciler is an optional extension of CommonReconciler
+// which stores the time of the next retry of the failed reconcile in the CR status
+type RequeueStatusReconciler interface {
+	UpdateNextRetryTime(nextRetry *metav1.Time)
+}
+
+// StandbyStatusReconciler is an optional extension of CommonReconciler
+// which stores the deployment version mismatch in the CR status
+type StandbyStatusReconciler interface {
+	UpdateStandbyStatus(status types.StandbyStatus)
+	// GetStandbyStatus returns the stored status, nil if it is not set
+	GetStandbyStatus() *types.StandbyStatus
+}
+
+// DriftStatusReconciler is an optional extension of CommonReconciler
+// which stores the result of the drift check in the CR status
+type DriftStatusReconciler interface {
+	UpdateDriftStatus(status types.DriftStatus)
+}
+
 type DefaultCommonReconciler struct {
 	CommonReconciler
 }
@@ -57,21 +94,81 @@ type ReconcileCommonService struct {
 	Builder          ExecutableBuilder
 	DREnabled        bool
 	Reconciler       CommonReconciler
+	// Checkpoints enables resuming of a failed reconcile from the failed step
+	// if the spec has not been changed since the failure. Only completed steps
+	// declaring with DependentExecutable that they produce no context variables are skipped.
+	Checkpoints bool
+	// Timeout limits the duration of the whole reconcile pipeline if set
+	Timeout time.Duration
+	// Interceptors are applied to every step in the registration order.
+	// DefaultStepInterceptors are used if not set, include LoggingInterceptor to keep the step logs.
+	Interceptors []StepInterceptor
+	// Recorder enables Kubernetes events on the CR, the same events are deduplicated
+	Recorder    record.EventRecorder
+	events      *EventEmitter
+	eventsMutex sync.Mutex
+	// RequeuePolicy controls retries of failed cycles and resync of succeeded ones, DefaultRequeuePolicy is used if not set
+	RequeuePolicy *RequeuePolicy
+	// failures counts consecutive failed cycles per CR
+	failures      map[string]int
+	failuresMutex sync.Mutex
+	// standby holds CRs which are left to the operator of another deployment version
+	standby      map[string]bool
+	standbyMutex sync.Mutex
+	// CleanupBuilder is run on the CR deletion. If it is set, the CR is protected with the Finalizer
+	// until the cleanup succeeds, the spec config map is removed after the cleanup as well.
+	CleanupBuilder ExecutableBuilder
+	// Finalizer is the name of the finalizer managed with CleanupBuilder, constants.CleanupFinalizer is used if not set
+	Finalizer string
+	// Retention keeps the resources of the listed kinds on the CR deletion, other resources are deleted
+	Retention RetentionPolicies
+	// paused holds CRs which reconciliation is frozen with the pause annotation
+	paused      map[string]bool
+	pausedMutex sync.Mutex
+	// DriftDetection enables the check of the live objects on the reconciles without spec changes if set
+	DriftDetection *DriftDetection
+	// drift holds the last reported drift per CR
+	drift      map[string]string
+	driftMutex sync.Mutex
 }
 
 func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcile.Request) (result reconcile.Result, reconcileError error) {
 	logger := GetLogger(getEnvAsBool("DEBUG_LOG", true))
 
+	// Dry run only plans the execution and leaves the cluster, the CR status and its events untouched
+	dryRun := r.Executor.IsDryRun()
+
 	crHandler := DefaultCRStatusHandler{
 		Reconciler: r.Reconciler,
 		KubeClient: r.Client,
+		DREnabled:  r.DRBuilder != nil,
+	}
+	if !dryRun {
+		crHandler.Events = r.eventEmitter()
 	}
 
 	var consulClient consul.ConsulClient
 	var consulErr error
 
-	//we always return error == nil, this way we implement our own logic of reconcile retries
+	//we always return error == nil, this way we implement our own logic of reconcile retries with RequeuePolicy
 	var executionErrResult error
+	var checkpoints CheckpointStore
+	// the standby cycle is neither failed nor succeeded, its requeue is set explicitly
+	var standby bool
+	// the deleted CR is not requeued
+	var deleted bool
+	// the paused cycle does nothing and is checked again on the resync
+	var paused bool
+	report := NewExecutionReportCollector()
+
+	// Spans of the steps are children of the reconcile span, the span is ended after the status is committed
+	tracedCtx, reconcileSpan := tracing.StartSpan(ctx, "Reconcile",
+		tracing.NamespaceKey.String(request.Namespace), tracing.CRNameKey.String(request.Name))
+	reconcileStart := time.Now()
+	defer func() {
+		tracing.EndSpan(reconcileSpan, executionErrResult)
+		metrics.ObserveReconcile(request.Namespace, request.Name, reconcileStart, executionErrResult)
+	}()
 
 	defer func() {
 		panicStackTrace := string(debug.Stack())
@@ -85,9 +182,11 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 			case error:
 				var dre *DRExecutionError
 				if errors.As(err.(error), &dre) {
-					statusErr := crHandler.SetDRStatus("failed").Commit()
-					if statusErr != nil {
-						logger.Sugar().Errorf("Failed to update DR status to 'failed', err: %v", statusErr)
+					if !dryRun {
+						statusErr := crHandler.SetDRStatus("failed").Commit()
+						if statusErr != nil {
+							logger.Sugar().Errorf("Failed to update DR status to 'failed', err: %v", statusErr)
+						}
 					}
 					logger.Error(v.Error() + "\n" + panicStackTrace)
 					return
@@ -97,23 +196,70 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 
 			errMsg = stringErrorMsg + "\n" + panicStackTrace
 		} else if executionErrResult != nil {
-			// Usual exception
+			// Usual exception, panics of the steps are converted to StepError by the executor
+			var stepErr *StepError
+			if errors.As(executionErrResult, &stepErr) && stepErr.Stack != "" {
+				logger.Error(fmt.Sprintf("Step %s panicked: %v\n%s", stepErr.Path, stepErr.Err, stepErr.Stack))
+			}
+			var validationErr *ValidationError
+			if errors.As(executionErrResult, &validationErr) {
+				for _, fieldErr := range validationErr.Errors {
+					logger.Error(fmt.Sprintf("Validation of step %s failed: %v", fieldErr.Step, fieldErr))
+				}
+			}
+			var dre *DRExecutionError
+			if errors.As(executionErrResult, &dre) {
+				logger.Error(executionErrResult.Error())
+				if dryRun {
+					return
+				}
+				statusErr := crHandler.SetDRStatus("failed").Commit()
+				if statusErr != nil {
+					logger.Sugar().Errorf("Failed to update DR status to 'failed', err: %v", statusErr)
+				}
+				result = r.requeueAfterFailure(request, executionErrResult)
+				return
+			}
 			errMsg = executionErrResult.Error()
 		}
 
-		if errMsg != "" {
+		if errMsg != "" && dryRun {
+			logger.Error("Dry run exception: " + errMsg)
+		} else if errMsg != "" {
 			resultMsg := "Reconciliation exception: " + errMsg
 			logger.Error(resultMsg)
-			executionErrResult = &ExecutionError{Msg: resultMsg}
-
-			statusErr := crHandler.SetCRCondition(true, "Failed", executionErrResult, "ReconcileCycleFailed").SetDRStatus("failed").Commit()
+			rollbackStatus := NewRollbackStatus(executionErrResult)
+			if rollbackStatus != nil && checkpoints != nil {
+				// Rolled back steps have to be executed again
+				clearCheckpoints(checkpoints, logger)
+			}
+			executionErrResult = &ExecutionError{Msg: resultMsg, Err: executionErrResult}
+
+			result = r.requeueAfterFailure(request, executionErrResult)
+			logger.Info(fmt.Sprintf("Reconcile cycle is scheduled to be retried %s", describeRequeue(result)))
+			statusHandler := crHandler.SetCRCondition(true, "Failed", executionErrResult, "ReconcileCycleFailed").SetDRStatus("failed").
+				SetExecutionReport(report.Report()).SetNextRetryTime(nextRetryTime(result))
+			if rollbackStatus != nil {
+				statusHandler = statusHandler.SetRollbackStatus(*rollbackStatus)
+			}
+			statusErr := statusHandler.Commit()
 			if statusErr != nil {
 				logger.Sugar().Errorf("Failed to update CR status, err: %v", statusErr)
 			}
+		} else if !dryRun && reconcileError == nil && !standby && !deleted && !paused {
+			result = r.requeueAfterSuccess(request)
 		}
 
 	}()
 
+	// Reconcile context is cancelled on operator shutdown, steps get it with GetContext
+	pipelineCtx := tracedCtx
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		pipelineCtx, cancel = context.WithTimeout(tracedCtx, r.Timeout)
+		defer cancel()
+	}
+
 	r.Reconciler.SetServiceInstance(r.Client, request)
 	deploymentContext := GetExecutionContext(map[string]interface{}{
 		constants.ContextSpec:                       r.Reconciler.GetInstance(),
@@ -122,11 +268,17 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 		constants.ContextClient:                     r.Client,
 		constants.ContextKubeClient:                 r.KubeConfig,
 		constants.ContextLogger:                     logger,
-		constants.ContextVault:                      vault.NewVaulterHelperImpl(vault.NewVaultClientImpl(r.Reconciler.GetVaultRegistration())),
+		constants.ContextVault:                      vault.NewVaulterHelperImpl(vault.NewVaultClientImpl(r.Reconciler.GetVaultRegistration()).WithContext(pipelineCtx)),
 		constants.ContextConsulRegistration:         r.Reconciler.GetConsulRegistration(),
 		constants.ContextConsulServiceRegistrations: r.Reconciler.GetConsulServiceRegistrations(),
 		constants.ContextHashConfigMap:              r.Reconciler.GetConfigMapName(),
+		constants.ContextDryRun:                     dryRun,
+		constants.ContextExecutionReport:            report,
+		constants.ContextGoContext:                  pipelineCtx,
+		constants.ContextStepInterceptors:           r.stepInterceptors(),
+		constants.ContextEventEmitter:               crHandler.Events,
 	})
+	EmitNormalEvent(deploymentContext, EventReasonReconcileStarted, "Reconcile cycle started")
 
 	deploymentVersion := getEnv("DEPLOYMENT_VERSION", "")
 	sleepTime := getEnvAsInt("DEPLOYMENT_VERSION_MISMATCH_SLEEP_SECONDS", 5*60)
@@ -134,11 +286,56 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 	if deploymentVersion != "" {
 		crDeploymentVersion := r.Reconciler.GetDeploymentVersion()
 		logger.Debug(fmt.Sprintf("Stored deployment version: %s . Current CR deployment version: %s", deploymentVersion, crDeploymentVersion))
-		if deploymentVersion != crDeploymentVersion {
+		mismatch := deploymentVersion != crDeploymentVersion
+		metrics.SetDeploymentVersionMismatch(request.Namespace, request.Name, mismatch)
+		if !dryRun && (r.setStandby(request, mismatch) || r.isStandbyStatusStale(mismatch, deploymentVersion, crDeploymentVersion)) {
+			r.commitStandbyStatus(crHandler, deploymentContext, mismatch, deploymentVersion, crDeploymentVersion, logger)
+		}
+		if mismatch {
+			// The CR is handled by the operator of its deployment version, e.g. during blue/green upgrade of the operator.
+			// Reconciles of other CRs keep running, the CR is checked again later.
+			logger.Info(fmt.Sprintf("Deployment version mismatch, reconcile is in standby. Checking again in %v seconds", sleepTime))
+			standby = true
+			result = reconcile.Result{RequeueAfter: time.Duration(sleepTime) * time.Second}
+			return
+		}
+	}
 
-			logger.Info(fmt.Sprintf("Deployment version mismatch. Sleeping for %v seconds and exit...", sleepTime))
-			time.Sleep(time.Duration(sleepTime) * time.Second)
-			os.Exit(0)
+	instance := r.Reconciler.GetInstance()
+	// The pause freezes the CR completely, including the cleanup on its deletion
+	if isPersisted(instance) {
+		paused = isPaused(instance)
+		if (r.setPaused(request, paused) || r.isPausedConditionStale(paused)) && !dryRun {
+			r.commitPausedStatus(crHandler, deploymentContext, paused, logger)
+		}
+		if paused {
+			logger.Info(fmt.Sprintf("Reconcile is paused with %s annotation, skipping", constants.PauseAnnotation))
+			result = r.requeuePolicy().OnSuccess()
+			return
+		}
+	}
+
+	if isPersisted(instance) && !instance.GetDeletionTimestamp().IsZero() {
+		nodeIP := getEnv("HOST_IP", "")
+		consulClient, consulErr = consul.NewConsulClientImplWithContext(pipelineCtx, nodeIP, "", r.Reconciler.GetConsulRegistration(), r.KubeConfig, logger)
+		PanicError(consulErr, logger.Error, "Error is happened during consul client creation")
+		ConsulKey.Set(deploymentContext, consulClient)
+
+		//error will be catched by defer above
+		executionErrResult = r.cleanup(deploymentContext, instance, logger)
+		if dryRun {
+			logPlan(deploymentContext, logger)
+		}
+		if executionErrResult == nil && !dryRun {
+			deleted = true
+			r.forget(request)
+		}
+		return
+	}
+	if isPersisted(instance) && !dryRun {
+		if err := r.ensureFinalizer(deploymentContext, instance); err != nil {
+			executionErrResult = &ExecutionError{Msg: fmt.Sprintf("Failed to add finalizer %s", r.finalizer()), Err: err}
+			return
 		}
 	}
 
@@ -158,10 +355,14 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 	adminSecret := r.Reconciler.GetAdminSecretName()
 	if adminSecret != "" {
 		err := informer.Watch([]string{r.Reconciler.GetAdminSecretName()}, func() {
+			metrics.PasswordRotations.WithLabelValues(request.Namespace, request.Name).Inc()
+			// The callback runs after Reconcile returns, so the contexts of the reconcile are not used
+			watchContext, cancel := r.newWatchContext(ctx, deploymentContext)
+			defer cancel()
 
 			if r.Reconciler.UpdatePassWithFullReconcile() {
 
-				err := wait.PollUntilContextTimeout(context.Background(), time.Second, time.Duration(sleepTime)*time.Second, true,
+				err := wait.PollUntilContextTimeout(GetContext(watchContext), time.Second, time.Duration(sleepTime)*time.Second, true,
 					func(ctx context.Context) (done bool, err error) {
 						changed, err := manager.AreCredsChanged([]string{r.Reconciler.GetAdminSecretName()})
 						return !changed && err == nil, nil
@@ -171,17 +372,19 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 					logger.Error(fmt.Sprintf("Failed to wait secret and secret old is identical, err: %v", err))
 				}
 
-				resetErr := doResetSpec(deploymentContext)
+				resetErr := doResetSpec(watchContext)
 				if resetErr != nil {
 					logger.Error(fmt.Sprintf("Failed to reset spec config map, err: %v", resetErr))
 				}
-				r.Reconcile(ctx, request)
+				r.Reconcile(context.WithoutCancel(ctx), request)
 			} else {
-				updateErr := r.Reconciler.UpdatePassword().Execute(deploymentContext)
+				updateErr := r.Reconciler.UpdatePassword().Execute(watchContext)
 				if updateErr != nil {
 					logger.Error(fmt.Sprintf("Failed to update password, err: %v", updateErr))
+					EmitWarningEvent(watchContext, EventReasonPasswordUpdateFailed, fmt.Sprintf("Failed to update password: %v", updateErr))
 				} else {
 					logger.Info("Password updated")
+					EmitNormalEvent(watchContext, EventReasonPasswordUpdated, "Password updated")
 				}
 			}
 		})
@@ -207,7 +410,27 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 
 	}
 
-	if specHasChanges && !isCurrentStatus(r.Reconciler, "Successful") {
+	// The spec hash is stored by the failed cycle as well, so its retry has to run the pipeline again
+	if !specHasChanges && r.hasFailed(request) {
+		logger.Info("The last reconcile cycle has failed, retrying it")
+		specHasChanges = true
+	}
+
+	forced := isPersisted(instance) && isForceReconcileRequested(instance) && !dryRun
+	if forced {
+		logger.Info(fmt.Sprintf("Full reconcile is forced with %s annotation. Continue with deleted %v config map.",
+			constants.ForceReconcileAnnotation, r.Reconciler.GetConfigMapName()))
+		EmitNormalEvent(deploymentContext, EventReasonForceReconcile, "Full reconcile is forced with the annotation")
+
+		reconcileError = doResetSpec(deploymentContext)
+		CheckSpecChange(deploymentContext, r.Reconciler.GetSpec(), "spec-summary")
+		if reconcileError != nil {
+			return
+		}
+		specHasChanges = true
+	}
+
+	if specHasChanges && !forced && !isCurrentStatus(r.Reconciler, "Successful") && !dryRun {
 		logger.Info(fmt.Sprintf(`Looks like the last deploy has failed and this is a new one. 
 			Continue with deleted %v config map to run full reconcile.`, r.Reconciler.GetConfigMapName()))
 
@@ -225,27 +448,37 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 		}
 	}
 
-	deploymentContext.Set(constants.ContextSpecHasChanges, specHasChanges)
+	if r.Checkpoints && !dryRun {
+		checkpoints = r.initCheckpoints(request, forced, logger)
+		if checkpoints != nil {
+			CheckpointStoreKey.Set(deploymentContext, checkpoints)
+		}
+	}
+
+	SpecHasChangesKey.Set(deploymentContext, specHasChanges)
 
 	if r.PredeployBuilder != nil {
 		logger.Info("Performing pre-deploy...")
 		r.Executor.SetExecutable(r.PredeployBuilder.Build(deploymentContext))
 		//error will be catched by defer above
 		executionErrResult = r.Executor.Execute(deploymentContext)
+		logPlan(deploymentContext, logger)
 		logger.Info("Pre-deploy is finished")
 	}
 
 	if specHasChanges && executionErrResult == nil {
-		statusErr := crHandler.SetCRCondition(true, "In Progress", nil, "ReconcileCycleInProgress").SetDRStatus("running").Commit()
-		if statusErr != nil {
-			logger.Sugar().Errorf("Failed to update CR status, err: %v", statusErr)
+		if !dryRun {
+			statusErr := crHandler.SetCRCondition(true, "In Progress", nil, "ReconcileCycleInProgress").SetDRStatus("running").Commit()
+			if statusErr != nil {
+				logger.Sugar().Errorf("Failed to update CR status, err: %v", statusErr)
+			}
 		}
 
 		nodeIP := getEnv("HOST_IP", "")
 		//consulClient, consulErr = consul.NewConsulClientImpl(nodeIP, request.Namespace, r.Reconciler.GetConsulRegistration(), r.KubeConfig, logger)
-		consulClient, consulErr = consul.NewConsulClientImpl(nodeIP, "", r.Reconciler.GetConsulRegistration(), r.KubeConfig, logger)
+		consulClient, consulErr = consul.NewConsulClientImplWithContext(pipelineCtx, nodeIP, "", r.Reconciler.GetConsulRegistration(), r.KubeConfig, logger)
 		PanicError(consulErr, logger.Error, "Error is happened during consul client creation")
-		deploymentContext.Set(constants.ContextConsul, consulClient)
+		ConsulKey.Set(deploymentContext, consulClient)
 
 		r.Executor.SetExecutable(r.Builder.Build(deploymentContext))
 
@@ -253,19 +486,35 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 
 		//error will be catched by defer above
 		executionErrResult = r.Executor.Execute(deploymentContext)
+		if dryRun {
+			logPlan(deploymentContext, logger)
+			return
+		}
 		if executionErrResult == nil {
+			if checkpoints != nil {
+				clearCheckpoints(checkpoints, logger)
+				CheckpointStoreKey.Set(deploymentContext, nil)
+			}
+
 			// Update last success execution version
-			statusErr := crHandler.SetCRCondition(true, "Successful", nil, "ReconcileCycleSucceeded").Commit()
+			statusErr := crHandler.SetCRCondition(true, "Successful", nil, "ReconcileCycleSucceeded").
+				SetExecutionReport(report.Report()).SetNextRetryTime(nil).Commit()
 			if statusErr != nil {
 				logger.Sugar().Errorf("Failed to update CR status, err: %v", statusErr)
 			}
 			logger.Info("Reconcile cycle succeeded")
 
+			if forced {
+				if err := r.acknowledgeForceReconcile(deploymentContext, instance); err != nil {
+					logger.Warn(fmt.Sprintf("Failed to remove %s annotation, err: %v", constants.ForceReconcileAnnotation, err))
+				}
+			}
+
 			if r.DRBuilder != nil {
 				r.Executor.SetExecutable(r.DRBuilder.Build(deploymentContext))
 				executionErrResult = r.Executor.Execute(deploymentContext)
 
-				statusErr := crHandler.SetDRStatus("done").Commit()
+				statusErr := crHandler.SetDRStatus("done").SetExecutionReport(report.Report()).Commit()
 				if statusErr != nil {
 					logger.Sugar().Errorf("Failed to update CR status, err: %v", statusErr)
 				}
@@ -273,9 +522,223 @@ func (r *ReconcileCommonService) Reconcile(ctx context.Context, request reconcil
 		}
 
 	}
+
+	// The pipeline brings the objects to the desired state itself, so only the reconciles without changes are checked
+	if !specHasChanges && executionErrResult == nil && r.DriftDetection != nil && !dryRun {
+		r.checkDrift(deploymentContext, crHandler, request, logger)
+	}
 	return
 }
 
+func (r *ReconcileCommonService) requeuePolicy() RequeuePolicy {
+	if r.RequeuePolicy != nil {
+		return *r.RequeuePolicy
+	}
+	return DefaultRequeuePolicy()
+}
+
+// requeueAfterFailure counts the consecutive failure of the CR and returns the result according to the policy
+func (r *ReconcileCommonService) requeueAfterFailure(request reconcile.Request, err error) reconcile.Result {
+	r.failuresMutex.Lock()
+	if r.failures == nil {
+		r.failures = make(map[string]int)
+	}
+	r.failures[request.String()]++
+	failures := r.failures[request.String()]
+	r.failuresMutex.Unlock()
+
+	return r.requeuePolicy().OnFailure(failures, err)
+}
+
+// requeueAfterSuccess resets the failures of the CR and returns the resync result, drift checks may shorten the resync
+func (r *ReconcileCommonService) requeueAfterSuccess(request reconcile.Request) reconcile.Result {
+	r.failuresMutex.Lock()
+	delete(r.failures, request.String())
+	r.failuresMutex.Unlock()
+
+	result := r.requeuePolicy().OnSuccess()
+	if r.DriftDetection != nil && r.DriftDetection.Interval > 0 &&
+		(result.RequeueAfter == 0 || result.RequeueAfter > r.DriftDetection.Interval) {
+		result.RequeueAfter = r.DriftDetection.Interval
+	}
+	return result
+}
+
+// newWatchContext returns the execution context for the callbacks run after Reconcile returns.
+// Its Go context and Vault client are not cancelled with the reconcile, Timeout limits every callback run if set.
+func (r *ReconcileCommonService) newWatchContext(ctx context.Context, deploymentContext ExecutionContext) (ExecutionContext, context.CancelFunc) {
+	goCtx, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+	if r.Timeout > 0 {
+		goCtx, cancel = context.WithTimeout(goCtx, r.Timeout)
+	}
+	vaultHelper := vault.NewVaulterHelperImpl(vault.NewVaultClientImpl(r.Reconciler.GetVaultRegistration()).WithContext(goCtx))
+	watchContext := withValue(deploymentContext, GoContextKey.Name(), goCtx)
+	return withValue(watchContext, VaultKey.Name(), vaultHelper), cancel
+}
+
+// hasFailed reports whether the last cycle of the CR has failed, the status covers the failures before the operator restart
+func (r *ReconcileCommonService) hasFailed(request reconcile.Request) bool {
+	r.failuresMutex.Lock()
+	failures := r.failures[request.String()]
+	r.failuresMutex.Unlock()
+
+	return failures > 0 || isCurrentStatus(r.Reconciler, "Failed")
+}
+
+// forget removes the state of the deleted CR
+func (r *ReconcileCommonService) forget(request reconcile.Request) {
+	r.failuresMutex.Lock()
+	delete(r.failures, request.String())
+	r.failuresMutex.Unlock()
+
+	r.standbyMutex.Lock()
+	delete(r.standby, request.String())
+	r.standbyMutex.Unlock()
+
+	r.pausedMutex.Lock()
+	delete(r.paused, request.String())
+	r.pausedMutex.Unlock()
+
+	r.driftMutex.Lock()
+	delete(r.drift, request.String())
+	r.driftMutex.Unlock()
+}
+
+// nextRetryTime returns nil if the CR is not requeued
+func nextRetryTime(result reconcile.Result) *metav1.Time {
+	if result.RequeueAfter > 0 {
+		nextRetry := metav1.NewTime(time.Now().Add(result.RequeueAfter))
+		return &nextRetry
+	}
+	if result.Requeue {
+		now := metav1.Now()
+		return &now
+	}
+	return nil
+}
+
+func describeRequeue(result reconcile.Result) string {
+	if result.RequeueAfter > 0 {
+		return "in " + result.RequeueAfter.String()
+	}
+	if result.Requeue {
+		return "immediately"
+	}
+	return "on the next CR change"
+}
+
+// setStandby stores the standby state of the CR and reports whether it has changed
+func (r *ReconcileCommonService) setStandby(request reconcile.Request, standby bool) bool {
+	r.standbyMutex.Lock()
+	defer r.standbyMutex.Unlock()
+	if r.standby == nil {
+		r.standby = make(map[string]bool)
+	}
+	if r.standby[request.String()] == standby {
+		return false
+	}
+	if standby {
+		r.standby[request.String()] = true
+	} else {
+		delete(r.standby, request.String())
+	}
+	return true
+}
+
+// isStandbyStatusStale reports whether the standby status of the CR doesn't match the deployment versions,
+// e.g. the standby is reported by the operator of another version or before the operator restart
+func (r *ReconcileCommonService) isStandbyStatusStale(standby bool, operatorVersion string, crVersion string) bool {
+	reconciler, ok := r.Reconciler.(StandbyStatusReconciler)
+	if !ok {
+		return false
+	}
+	status := reconciler.GetStandbyStatus()
+	if status == nil || !status.Standby {
+		return standby
+	}
+	return !standby || status.OperatorVersion != operatorVersion || status.CRVersion != crVersion
+}
+
+// commitStandbyStatus reports the standby transition, the status condition is left to the active operator
+func (r *ReconcileCommonService) commitStandbyStatus(crHandler CRStatusHandler, ctx ExecutionContext, standby bool,
+	operatorVersion string, crVersion string, logger *zap.Logger) {
+	status := types.StandbyStatus{
+		Standby:            standby,
+		OperatorVersion:    operatorVersion,
+		CRVersion:          crVersion,
+		LastTransitionTime: metav1.Now(),
+	}
+	if standby {
+		status.Message = fmt.Sprintf("Operator deployment version %s doesn't match CR deployment version %s", operatorVersion, crVersion)
+		EmitNormalEvent(ctx, EventReasonStandby, status.Message)
+	} else {
+		EmitNormalEvent(ctx, EventReasonStandbyFinished, "Deployment versions of the operator and CR match, reconcile is resumed")
+	}
+	if _, ok := r.Reconciler.(StandbyStatusReconciler); !ok {
+		return
+	}
+	if err := crHandler.SetStandbyStatus(status).Commit(); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to update standby status, err: %v", err))
+	}
+}
+
+// eventEmitter returns nil if Recorder is not set, the emitter is kept between reconciles for deduplication
+func (r *ReconcileCommonService) eventEmitter() *EventEmitter {
+	if r.Recorder == nil {
+		return nil
+	}
+	r.eventsMutex.Lock()
+	defer r.eventsMutex.Unlock()
+	if r.events == nil || r.events.Recorder != r.Recorder {
+		r.events = NewEventEmitter(r.Recorder)
+	}
+	return r.events
+}
+
+// initCheckpoints loads completion markers of the previous run.
+// The markers are bound to the spec hash, so the retry of the failed run with the same spec is resumed.
+func (r *ReconcileCommonService) initCheckpoints(request reconcile.Request, forced bool, logger *zap.Logger) CheckpointStore {
+	specHash, hashErr := GetSpecHash(r.Reconciler.GetSpec())
+	if hashErr != nil {
+		logger.Warn(fmt.Sprintf("Failed to calculate spec hash, checkpoints are disabled, err: %v", hashErr))
+		return nil
+	}
+
+	checkpoints, err := NewConfigMapCheckpointStore(r.Client, r.Reconciler.GetConfigMapName()+CheckpointConfigMapSuffix, request.Namespace, specHash)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load checkpoints, checkpoints are disabled, err: %v", err))
+		return nil
+	}
+
+	if forced || getEnvAsBool("FORCE_FULL_RECONCILE", false) {
+		logger.Info("Full reconcile is forced, checkpoints are cleared")
+		clearCheckpoints(checkpoints, logger)
+	} else if checkpoints.HasProgress() {
+		logger.Info("The last reconcile has failed and the spec is not changed. Resuming from the failed step")
+	}
+
+	return checkpoints
+}
+
+func (r *ReconcileCommonService) stepInterceptors() []StepInterceptor {
+	if r.Interceptors == nil {
+		return DefaultStepInterceptors()
+	}
+	return r.Interceptors
+}
+
+func clearCheckpoints(checkpoints CheckpointStore, logger *zap.Logger) {
+	if err := checkpoints.Clear(); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to clear checkpoints, err: %v", err))
+	}
+}
+
+func logPlan(ctx ExecutionContext, logger *zap.Logger) {
+	if plan := GetExecutionPlan(ctx); plan != nil {
+		plan.Log(logger)
+	}
+}
+
 func isCurrentStatus(reconciler CommonReconciler, statusType string) bool {
 	statusConditions := reconciler.GetStatus()
 	if statusConditions != nil {
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sTypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// testServiceReconciler keeps the legacy status of the Pod CR in its status message
+type testServiceReconciler struct {
+	testConditionsReconciler
+}
+
+func (r *testServiceReconciler) SetServiceInstance(kubeClient client.Client, request reconcile.Request) {
+	r.instance = &v1.Pod{}
+	if err := kubeClient.Get(context.Background(), request.NamespacedName, r.instance); err != nil {
+		r.instance = nil
+	}
+}
+
+func (r *testServiceReconciler) GetStatus() *types.ServiceStatusCondition {
+	if r.instance == nil || r.instance.Status.Message == "" {
+		return nil
+	}
+	return &types.ServiceStatusCondition{Type: r.instance.Status.Message}
+}
+
+func (r *testServiceReconciler) GetSpec() interface{} {
+	return r.instance.Spec
+}
+
+func (r *testServiceReconciler) GetConfigMapName() string {
+	return "cr-hash"
+}
+
+func (r *testServiceReconciler) GetDeploymentVersion() string {
+	return r.instance.Labels["deploymentVersion"]
+}
+
+func (r *testServiceReconciler) GetVaultRegistration() *types.VaultRegistration {
+	return nil
+}
+
+func (r *testServiceReconciler) GetConsulRegistration() *types.ConsulRegistration {
+	return nil
+}
+
+func (r *testServiceReconciler) GetConsulServiceRegistrations() map[string]*types.AgentServiceRegistration {
+	return nil
+}
+
+func (r *testServiceReconciler) GetMessage() string {
+	return ""
+}
+
+func (r *testServiceReconciler) GetAdminSecretName() string {
+	return ""
+}
+
+var testRequest = reconcile.Request{NamespacedName: k8sTypes.NamespacedName{Namespace: "namespace", Name: "cr"}}
+
+// newTestReconcileService returns the service reconciling the Pod CR with the step built by Builder
+func newTestReconcileService(step Executable, objects ...client.Object) (*ReconcileCommonService, client.Client) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testRequest.Namespace, Name: testRequest.Name, Generation: 1}}
+	kubeClient := fake.NewClientBuilder().
+		WithObjects(append(objects, pod)...).
+		WithStatusSubresource(pod).
+		Build()
+	return &ReconcileCommonService{
+		Client:     kubeClient,
+		Scheme:     scheme.Scheme,
+		Executor:   DefaultExecutor(),
+		Builder:    &testBuilder{step: step},
+		Reconciler: &testServiceReconciler{},
+	}, kubeClient
+}
+
+func getTestCR(t *testing.T, kubeClient client.Client) *v1.Pod {
+	stored := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), testRequest.NamespacedName, stored))
+	return stored
+}
+
+type validatedTestStep struct {
+	testStep
+	validationErr error
+}
+
+func (r *validatedTestStep) Validate(ctx ExecutionContext) error {
+	return r.validationErr
+}
+
+func TestDryRunReconcile(t *testing.T) {
+	for name, validationErr := range map[string]error{
+		"Planned":          nil,
+		"Validation error": errors.New("invalid spec"),
+		"DR error":         &DRExecutionError{Msg: "DR is not possible"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			step := &validatedTestStep{validationErr: validationErr, testStep: testStep{executeFunc: func(ctx ExecutionContext) error {
+				t.Error("Step must not be executed during dry run")
+				return nil
+			}}}
+			r, kubeClient := newTestReconcileService(step)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+			r.Executor = PlanExecutor()
+			resourceVersion := getTestCR(t, kubeClient).ResourceVersion
+
+			result, err := r.Reconcile(context.Background(), testRequest)
+			assert.Nil(t, err)
+			assert.Equal(t, reconcile.Result{}, result)
+
+			stored := getTestCR(t, kubeClient)
+			assert.Equal(t, resourceVersion, stored.ResourceVersion)
+			assert.Empty(t, stored.Status.Message)
+			assert.Empty(t, stored.Status.Reason)
+			assert.Empty(t, recorder.Events)
+		})
+	}
+}
+
+// standbyTestReconciler keeps the standby status of the Pod CR in its Standby condition
+type standbyTestReconciler struct {
+	testServiceReconciler
+}
+
+func (r *standbyTestReconciler) UpdateStandbyStatus(status types.StandbyStatus) {
+	condition := v1.PodCondition{Type: "Standby", Status: v1.ConditionFalse, Reason: status.OperatorVersion, Message: status.CRVersion}
+	if status.Standby {
+		condition.Status = v1.ConditionTrue
+	}
+	r.instance.Status.Conditions = []v1.PodCondition{condition}
+}
+
+func (r *standbyTestReconciler) GetStandbyStatus() *types.StandbyStatus {
+	for _, condition := range r.instance.Status.Conditions {
+		if condition.Type == "Standby" {
+			return &types.StandbyStatus{
+				Standby:         condition.Status == v1.ConditionTrue,
+				OperatorVersion: condition.Reason,
+				CRVersion:       condition.Message,
+			}
+		}
+	}
+	return nil
+}
+
+func TestStandbyReconcile(t *testing.T) {
+	t.Setenv("DEPLOYMENT_VERSION", "v2")
+	t.Setenv("DEPLOYMENT_VERSION_MISMATCH_SLEEP_SECONDS", "60")
+	executions := 0
+	step := &testStep{executeFunc: func(ctx ExecutionContext) error {
+		executions++
+		return nil
+	}}
+	r, kubeClient := newTestReconcileService(step)
+	r.Reconciler = &standbyTestReconciler{}
+	cr := getTestCR(t, kubeClient)
+	cr.Labels = map[string]string{"deploymentVersion": "v1"}
+	assert.Nil(t, kubeClient.Update(context.Background(), cr))
+
+	result, err := r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+	assert.Equal(t, 0, executions)
+	stored := getTestCR(t, kubeClient)
+	assert.Equal(t, []v1.PodCondition{{Type: "Standby", Status: v1.ConditionTrue, Reason: "v2", Message: "v1"}}, stored.Status.Conditions)
+
+	// The operator of the CR version has no in-memory standby state, the stored status is cleared anyway
+	t.Setenv("DEPLOYMENT_VERSION", "v1")
+	r = &ReconcileCommonService{
+		Client:     kubeClient,
+		Scheme:     r.Scheme,
+		Executor:   DefaultExecutor(),
+		Builder:    r.Builder,
+		Reconciler: &standbyTestReconciler{},
+	}
+
+	_, err = r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, executions)
+	stored = getTestCR(t, kubeClient)
+	assert.Equal(t, []v1.PodCondition{{Type: "Standby", Status: v1.ConditionFalse, Reason: "v1", Message: "v1"}}, stored.Status.Conditions)
+}
+
+func TestWatchContext(t *testing.T) {
+	r, _ := newTestReconcileService(nil)
+	r.Timeout = time.Minute
+	reconcileCtx, cancelReconcile := context.WithCancel(context.Background())
+	deploymentContext := NewInitExecutionContext(map[string]interface{}{
+		constants.ContextGoContext: reconcileCtx,
+	})
+
+	// The reconcile is over, the callback still gets a live context with its own deadline
+	cancelReconcile()
+	watchContext, cancel := r.newWatchContext(reconcileCtx, deploymentContext)
+	defer cancel()
+	goCtx := GetContext(watchContext)
+	assert.Nil(t, goCtx.Err())
+	_, hasDeadline := goCtx.Deadline()
+	assert.True(t, hasDeadline)
+	assert.NotNil(t, VaultKey.MustGet(watchContext))
+	assert.Equal(t, reconcileCtx, GetContext(deploymentContext))
+
+	cancel()
+	assert.ErrorIs(t, goCtx.Err(), context.Canceled)
+}
@@ -1,12 +1,13 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
-	"strings"
+	"sync"
+	"time"
 
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
-	"go.uber.org/zap"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
 )
 
 type ExecutableCompound interface {
@@ -17,15 +18,28 @@ type ExecutableCompound interface {
 type DefaultCompound struct {
 	ExecutableCompound
 	executableSteps []Executable
+	// RollbackOnFailure enables compensation of already completed Rollbackable steps
+	// in reverse order if one of the steps fails, the steps of nested compounds are compensated as well
+	RollbackOnFailure bool
+	completedSteps    []Executable
+	completedMutex    sync.Mutex
+	interceptors      []StepInterceptor
 }
 
 func (r *DefaultCompound) AddStep(step Executable) {
 	r.executableSteps = append(r.executableSteps, step)
 }
 
-func (r *DefaultCompound) iterateOverSteps(stepFunc func(step Executable) error) error {
-	for _, element := range r.executableSteps {
-		err := stepFunc(element)
+// Use registers interceptors which are applied to all nested steps of the compound
+func (r *DefaultCompound) Use(interceptors ...StepInterceptor) {
+	r.interceptors = append(r.interceptors, interceptors...)
+}
+
+// iterateOverSteps calls stepFunc for every step with the context pointing to the step path
+func (r *DefaultCompound) iterateOverSteps(ctx ExecutionContext, stepFunc func(stepCtx ExecutionContext, step Executable) error) error {
+	segments := stepPathSegments(r.executableSteps)
+	for i, element := range r.executableSteps {
+		err := stepFunc(withStepPath(ctx, segments[i]), element)
 		if err != nil {
 			return err
 		}
@@ -33,30 +47,93 @@ func (r *DefaultCompound) iterateOverSteps(stepFunc func(step Executable) error)
 	return nil
 }
 
+// Validate runs validation of all steps and returns ValidationError with all failures
 func (r *DefaultCompound) Validate(ctx ExecutionContext) error {
-	return r.iterateOverSteps(
-		func(element Executable) error {
-			return element.Validate(ctx)
+	validationErr := &ValidationError{}
+	r.iterateOverSteps(ctx,
+		func(stepCtx ExecutionContext, element Executable) error {
+			validationErr.Add(GetStepPath(stepCtx), callRecovered(func() error { return element.Validate(stepCtx) }))
+			return nil
 		})
+	return validationErr.ErrorOrNil()
 }
 
 func (r *DefaultCompound) Execute(ctx ExecutionContext) error {
-	return r.iterateOverSteps(
-		func(element Executable) error {
-			if run, err := element.Condition(ctx); run {
-				logger := ctx.Get(constants.ContextLogger)
-				if logger != nil {
-					log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-					st := reflect.TypeOf(element)
-					stepName := strings.Split(st.String(), ".")[1]
-					log.Info(fmt.Sprintf("Step %s started", stepName))
-					defer log.Info(fmt.Sprintf("Step %s finished", stepName))
-				}
-				return element.Execute(ctx)
-			} else {
-				return err
-			}
+	ctx = withInterceptors(ctx, r.interceptors)
+	if r.RollbackOnFailure || isRollbackTracked(ctx) {
+		return r.executeWithRollback(ctx, func(stepFunc func(stepCtx ExecutionContext, step Executable) error) error {
+			return r.iterateOverSteps(ctx, stepFunc)
 		})
+	}
+	return r.iterateOverSteps(ctx, executeStep)
+}
+
+// executeStep checks the step condition and executes the step if it is satisfied
+func executeStep(ctx ExecutionContext, element Executable) error {
+	_, err := runStep(ctx, element)
+	return err
+}
+
+func runStep(ctx ExecutionContext, element Executable) (executed bool, err error) {
+	finish := recordStep(ctx)
+	ctx, span := startStepSpan(ctx, element)
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = newPanicStepError(ctx, recovered)
+		} else {
+			err = wrapStepError(ctx, err)
+		}
+		result := recordedResult(executed, err)
+		finish(result, err)
+		endStepSpan(span, result, err)
+		metrics.ObserveStep(GetStepName(element), result, start, err)
+		// parents of the failed step share its error, so only the origin is reported
+		var stepErr *StepError
+		if errors.As(err, &stepErr) && stepErr.Path == GetStepPath(ctx) {
+			EmitWarningEvent(ctx, EventReasonStepFailed, stepErr.Error())
+		}
+	}()
+
+	logger, hasLogger := LoggerKey.Lookup(ctx)
+	checkpoints := GetCheckpointStore(ctx)
+	stepPath := GetStepPath(ctx)
+	resumable := checkpoints != nil && isResumable(element)
+	if resumable && checkpoints.IsCompleted(stepPath) {
+		if hasLogger {
+			logger.Info(fmt.Sprintf("Step %s is already completed for the current spec, skipping", stepPath))
+		}
+		return false, nil
+	}
+
+	if err = checkCancelled(ctx); err != nil {
+		return false, err
+	}
+
+	if !runsInDeployType(ctx, element) {
+		return false, nil
+	}
+
+	if run, condErr := element.Condition(ctx); run {
+		executed, err = interceptStep(ctx, element)
+		if executed && err == nil && resumable {
+			if markErr := checkpoints.MarkCompleted(stepPath); markErr != nil && hasLogger {
+				logger.Warn(fmt.Sprintf("Failed to store checkpoint for step %s, err: %v", stepPath, markErr))
+			}
+		}
+		return executed, err
+	} else {
+		return false, condErr
+	}
+}
+
+// GetStepName returns the type name of the step
+func GetStepName(element Executable) string {
+	st := reflect.TypeOf(UnwrapStep(element))
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	return st.Name()
 }
 
 func (r *DefaultCompound) Condition(ctx ExecutionContext) (bool, error) {
@@ -69,18 +146,39 @@ const (
 	CleanDeploy MicroServiceDeployType = "CleanDeploy"
 	Update      MicroServiceDeployType = "Update"
 	Empty       MicroServiceDeployType = ""
+	// Upgrade is an update which changes the image version to a newer one
+	Upgrade MicroServiceDeployType = "Upgrade"
+	// Downgrade is an update which changes the image version to an older one
+	Downgrade MicroServiceDeployType = "Downgrade"
+	ScaleOut  MicroServiceDeployType = "ScaleOut"
+	ScaleIn   MicroServiceDeployType = "ScaleIn"
+	// CleanupRequired means the previous deployment has failed and its leftovers have to be removed.
+	// It is opt-in: GetMicroServiceDeployType returns CleanDeploy for such services, see GetMicroServiceDeployTypeWithCleanup.
+	CleanupRequired MicroServiceDeployType = "CleanupRequired"
+	// Uninstall means the CR is being deleted
+	Uninstall MicroServiceDeployType = "Uninstall"
 )
 
+// MicroServiceCompound runs its steps in a child scope of the execution context named after ServiceName,
+// so the steps of different services don't overwrite variables of each other
 type MicroServiceCompound struct {
 	DefaultCompound
 	ServiceName    string
 	CalcDeployType func(ctx ExecutionContext) (MicroServiceDeployType, error)
+	// ExportVars are copied from the service scope to the parent context after execution
+	ExportVars []string
+	// scope is the service scope of the last execution, the compensation of the steps runs in it
+	scope *ScopedExecutionContext
+}
+
+func (r *MicroServiceCompound) newScope(ctx ExecutionContext) *ScopedExecutionContext {
+	return NewScopedExecutionContext(ctx, r.ServiceName)
 }
 
 // TODO why the same code?
-func (r *MicroServiceCompound) Validate(ctx ExecutionContext) (executionErrResult error) {
+func (r *MicroServiceCompound) Validate(parentCtx ExecutionContext) (executionErrResult error) {
 	//Setting up current service deploy context
-	previousDeployType := GetCurrentDeployType(ctx)
+	ctx := r.newScope(parentCtx)
 
 	// Handling microservice steps exception
 	defer func() {
@@ -107,13 +205,10 @@ func (r *MicroServiceCompound) Validate(ctx ExecutionContext) (executionErrResul
 		}
 		if errMsg != "" {
 			//result = errMsg
-			executionErrResult = &ExecutionError{Msg: "Microservice validation exception: " + errMsg}
+			executionErrResult = &ExecutionError{Msg: "Microservice validation exception: " + errMsg, Err: executionErrResult}
 		}
 
 		//AddServiceDeployResultToContext(ctx, r.ServiceName, result)
-
-		//Setting previous type
-		SetCurrentDeployType(ctx, previousDeployType)
 	}()
 
 	//deployTypeForService := GetMicroServiceDeployType(ctx, r.ServiceName)
@@ -129,9 +224,10 @@ func (r *MicroServiceCompound) Validate(ctx ExecutionContext) (executionErrResul
 	return
 }
 
-func (r *MicroServiceCompound) Execute(ctx ExecutionContext) (executionErrResult error) {
+func (r *MicroServiceCompound) Execute(parentCtx ExecutionContext) (executionErrResult error) {
 	//Setting up current service deploy context
-	previousDeployType := GetCurrentDeployType(ctx)
+	ctx := r.newScope(parentCtx)
+	r.scope = ctx
 
 	// Handling microservice steps exception
 	defer func() {
@@ -144,13 +240,15 @@ func (r *MicroServiceCompound) Execute(ctx ExecutionContext) (executionErrResult
 		}
 		if errMsg != "" {
 			//result = errMsg
-			executionErrResult = &ExecutionError{Msg: "Microservice execution exception: " + errMsg}
+			executionErrResult = &ExecutionError{Msg: "Microservice execution exception: " + errMsg, Err: executionErrResult}
+			EmitWarningEvent(ctx, EventReasonServiceDeploymentFailed, fmt.Sprintf("Deployment of %s failed: %s", r.ServiceName, errMsg))
+		} else {
+			EmitNormalEvent(ctx, EventReasonServiceDeployed, fmt.Sprintf("Deployment of %s finished", r.ServiceName))
 		}
 
 		//AddServiceDeployResultToContext(ctx, r.ServiceName, result)
 
-		//Setting previous type
-		SetCurrentDeployType(ctx, previousDeployType)
+		ctx.Export(r.ExportVars...)
 	}()
 
 	//deployTypeForService := GetMicroServiceDeployType(ctx, r.ServiceName)
@@ -161,8 +259,23 @@ func (r *MicroServiceCompound) Execute(ctx ExecutionContext) (executionErrResult
 	}
 
 	SetCurrentDeployType(ctx, deployTypeForService)
+	EmitNormalEvent(ctx, EventReasonServiceDeploymentStarted, fmt.Sprintf("Deployment of %s started, deploy type: %s", r.ServiceName, deployTypeForService))
 
 	executionErrResult = r.DefaultCompound.Execute(ctx)
 
 	return
 }
+
+// Rollback compensates the steps completed during the last execution in the service scope they were executed in
+func (r *MicroServiceCompound) Rollback(parentCtx ExecutionContext) error {
+	_, errs := r.compensate(parentCtx)
+	return NewAggregatedError(errs)
+}
+
+func (r *MicroServiceCompound) compensate(parentCtx ExecutionContext) ([]string, []error) {
+	ctx := r.scope
+	if ctx == nil {
+		ctx = r.newScope(parentCtx)
+	}
+	return r.DefaultCompound.compensate(ctx)
+}
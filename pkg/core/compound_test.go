@@ -0,0 +1,564 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type testStep struct {
+	DefaultExecutable
+	executeFunc func(ctx ExecutionContext) error
+}
+
+func (r *testStep) Execute(ctx ExecutionContext) error {
+	if r.executeFunc != nil {
+		return r.executeFunc(ctx)
+	}
+	return nil
+}
+
+func TestParallelCompound(t *testing.T) {
+	t.Run("Bounded workers", func(t *testing.T) {
+		var running, maxRunning int32
+		compound := &ParallelCompound{MaxWorkers: 2}
+		for i := 0; i < 6; i++ {
+			compound.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					observed := atomic.LoadInt32(&maxRunning)
+					if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			}})
+		}
+
+		err := compound.Execute(NewDefaultExecutionContext())
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), maxRunning)
+	})
+
+	t.Run("Errors and panics are aggregated", func(t *testing.T) {
+		stepErr := errors.New("step failed")
+		var executed int32
+		compound := &ParallelCompound{}
+		compound.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			atomic.AddInt32(&executed, 1)
+			return stepErr
+		}})
+		compound.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			atomic.AddInt32(&executed, 1)
+			PanicError(errors.New("boom"), func(msg string, fields ...zap.Field) {}, "Panic in step")
+			return nil
+		}})
+		compound.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			atomic.AddInt32(&executed, 1)
+			ctx.Set("key", "value")
+			return nil
+		}})
+
+		ctx := NewDefaultExecutionContext()
+		err := compound.Execute(ctx)
+
+		var aggregated *AggregatedError
+		assert.True(t, errors.As(err, &aggregated))
+		assert.Equal(t, 2, len(aggregated.Errors))
+		assert.True(t, errors.Is(err, stepErr))
+		var executionErr *ExecutionError
+		assert.True(t, errors.As(err, &executionErr))
+		assert.Equal(t, int32(3), executed)
+		assert.Equal(t, "value", ctx.Get("key"))
+	})
+}
+
+type dependentTestStep struct {
+	testStep
+	requires []string
+	produces []string
+}
+
+func (r *dependentTestStep) Requires() []string {
+	return r.requires
+}
+
+func (r *dependentTestStep) Produces() []string {
+	return r.produces
+}
+
+func TestDAGCompound(t *testing.T) {
+	t.Run("Steps are ordered by dependencies", func(t *testing.T) {
+		var order []string
+		var orderLock sync.Mutex
+		record := func(name string, produces ...string) func(ctx ExecutionContext) error {
+			return func(ctx ExecutionContext) error {
+				orderLock.Lock()
+				order = append(order, name)
+				orderLock.Unlock()
+				for _, key := range produces {
+					ctx.Set(key, name)
+				}
+				return nil
+			}
+		}
+
+		compound := &DAGCompound{}
+		compound.AddStep(&dependentTestStep{
+			testStep: testStep{executeFunc: record("recycler")},
+			requires: []string{"pvcNames", "nodes"},
+		})
+		compound.AddStep(&dependentTestStep{
+			testStep: testStep{executeFunc: record("nodes", "nodes")},
+			produces: []string{"nodes"},
+		})
+		compound.AddStep(&dependentTestStep{
+			testStep: testStep{executeFunc: record("pvc", "pvcNames")},
+			produces: []string{"pvcNames"},
+		})
+
+		ctx := NewDefaultExecutionContext()
+		assert.Nil(t, compound.Validate(ctx))
+		assert.Nil(t, compound.Execute(ctx))
+		assert.Equal(t, 3, len(order))
+		assert.Equal(t, "recycler", order[2])
+	})
+
+	t.Run("Cycles and missing producers are detected", func(t *testing.T) {
+		compound := &DAGCompound{}
+		compound.AddStep(&dependentTestStep{requires: []string{"a"}, produces: []string{"b"}})
+		compound.AddStep(&dependentTestStep{requires: []string{"b"}, produces: []string{"a"}})
+		compound.AddStep(&dependentTestStep{requires: []string{"missing"}})
+
+		err := compound.Validate(NewInitExecutionContext(map[string]interface{}{}))
+		var aggregated *AggregatedError
+		assert.True(t, errors.As(err, &aggregated))
+		assert.Equal(t, 2, len(aggregated.Errors))
+	})
+}
+
+type rollbackableTestStep struct {
+	testStep
+	skip       bool
+	rolledBack *[]string
+	name       string
+}
+
+func (r *rollbackableTestStep) Condition(ctx ExecutionContext) (bool, error) {
+	return !r.skip, nil
+}
+
+func (r *rollbackableTestStep) Rollback(ctx ExecutionContext) error {
+	*r.rolledBack = append(*r.rolledBack, r.name)
+	return nil
+}
+
+func TestDefaultCompoundRollback(t *testing.T) {
+	var rolledBack []string
+	compound := &DefaultCompound{RollbackOnFailure: true}
+	compound.AddStep(&rollbackableTestStep{name: "first", rolledBack: &rolledBack})
+	compound.AddStep(&rollbackableTestStep{name: "skipped", skip: true, rolledBack: &rolledBack})
+	compound.AddStep(&rollbackableTestStep{name: "second", rolledBack: &rolledBack})
+	compound.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		panic("deployment failed")
+	}})
+
+	err := compound.Execute(NewDefaultExecutionContext())
+
+	var rollbackErr *RollbackError
+	assert.True(t, errors.As(err, &rollbackErr))
+	assert.Equal(t, []string{"second", "first"}, rolledBack)
+	var stepErr *StepError
+	assert.True(t, errors.As(rollbackErr.Err, &stepErr))
+	assert.Equal(t, "testStep", stepErr.Path)
+	assert.Equal(t, "deployment failed", stepErr.Err.Error())
+	assert.NotEmpty(t, stepErr.Stack)
+
+	status := NewRollbackStatus(err)
+	assert.Equal(t, RollbackSucceeded, status.Status)
+	assert.Equal(t, 2, len(status.Steps))
+}
+
+type compensatingTestStep struct {
+	testStep
+	rollbackFunc func(ctx ExecutionContext) error
+}
+
+func (r *compensatingTestStep) Rollback(ctx ExecutionContext) error {
+	return r.rollbackFunc(ctx)
+}
+
+func TestCompoundRollback(t *testing.T) {
+	failingStep := &testStep{executeFunc: func(ctx ExecutionContext) error {
+		return errors.New("deployment failed")
+	}}
+
+	t.Run("Parallel", func(t *testing.T) {
+		var rolledBack []string
+		compound := &ParallelCompound{DefaultCompound: DefaultCompound{RollbackOnFailure: true}, MaxWorkers: 1}
+		compound.AddStep(&rollbackableTestStep{name: "first", rolledBack: &rolledBack})
+		compound.AddStep(&rollbackableTestStep{name: "second", rolledBack: &rolledBack})
+		compound.AddStep(failingStep)
+
+		var rollbackErr *RollbackError
+		assert.True(t, errors.As(compound.Execute(NewDefaultExecutionContext()), &rollbackErr))
+		assert.Equal(t, []string{"second", "first"}, rolledBack)
+	})
+
+	t.Run("DAG", func(t *testing.T) {
+		var rolledBack []string
+		compound := &DAGCompound{DefaultCompound: DefaultCompound{RollbackOnFailure: true}, MaxWorkers: 1}
+		compound.AddStep(&rollbackableTestStep{name: "first", rolledBack: &rolledBack})
+		compound.AddStep(&rollbackableTestStep{name: "second", rolledBack: &rolledBack})
+		compound.AddStep(failingStep)
+
+		var rollbackErr *RollbackError
+		assert.True(t, errors.As(compound.Execute(NewDefaultExecutionContext()), &rollbackErr))
+		assert.Equal(t, []string{"second", "first"}, rolledBack)
+	})
+
+	t.Run("Service scope", func(t *testing.T) {
+		var rolledBackPVCs interface{}
+		service := &MicroServiceCompound{
+			ServiceName: "service",
+			CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+				return CleanDeploy, nil
+			},
+		}
+		service.AddStep(&compensatingTestStep{
+			testStep: testStep{executeFunc: func(ctx ExecutionContext) error {
+				ctx.Set("pvcNames", []string{"pvc-1"})
+				return nil
+			}},
+			rollbackFunc: func(ctx ExecutionContext) error {
+				rolledBackPVCs = ctx.Get("pvcNames")
+				return nil
+			},
+		})
+		root := &DefaultCompound{RollbackOnFailure: true}
+		root.AddStep(service)
+		root.AddStep(failingStep)
+
+		ctx := NewDefaultExecutionContext()
+		assert.NotNil(t, root.Execute(ctx))
+		assert.Nil(t, ctx.Get("pvcNames"))
+		assert.Equal(t, []string{"pvc-1"}, rolledBackPVCs)
+	})
+
+	t.Run("Nested compounds", func(t *testing.T) {
+		var rolledBack []string
+		completed := &DefaultCompound{}
+		completed.AddStep(&rollbackableTestStep{name: "first", rolledBack: &rolledBack})
+		failed := &ParallelCompound{MaxWorkers: 1}
+		failed.AddStep(&rollbackableTestStep{name: "second", rolledBack: &rolledBack})
+		failed.AddStep(failingStep)
+		root := &DefaultCompound{RollbackOnFailure: true}
+		root.AddStep(completed)
+		root.AddStep(failed)
+
+		var rollbackErr *RollbackError
+		assert.True(t, errors.As(root.Execute(NewDefaultExecutionContext()), &rollbackErr))
+		assert.Equal(t, []string{"second", "first"}, rolledBack)
+		assert.Equal(t, []string{"rollbackableTestStep", "rollbackableTestStep"}, rollbackErr.RolledBackSteps)
+	})
+}
+
+func TestPlanExecutor(t *testing.T) {
+	service := &MicroServiceCompound{
+		CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+			return CleanDeploy, nil
+		},
+	}
+	service.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		t.Error("Step must not be executed during planning")
+		return nil
+	}})
+	service.AddStep(&rollbackableTestStep{skip: true})
+	root := &DefaultCompound{}
+	root.AddStep(service)
+
+	executor := PlanExecutor()
+	executor.SetExecutable(root)
+	ctx := NewDefaultExecutionContext()
+	assert.Nil(t, executor.Execute(ctx))
+	assert.True(t, executor.IsDryRun())
+
+	plan := GetExecutionPlan(ctx)
+	assert.Equal(t, []PlanEntry{
+		{Path: "DefaultCompound", Action: PlanRun},
+		{Path: "DefaultCompound/MicroServiceCompound", Action: PlanRun},
+		{Path: "DefaultCompound/MicroServiceCompound/testStep", Action: PlanRun, DeployType: CleanDeploy},
+		{Path: "DefaultCompound/MicroServiceCompound/rollbackableTestStep", Action: PlanSkip, DeployType: CleanDeploy, Reason: "Condition is not satisfied"},
+	}, plan.Entries)
+	assert.False(t, plan.HasFailures())
+}
+
+func TestExecutionReport(t *testing.T) {
+	root := &DefaultCompound{}
+	root.AddStep(&rollbackableTestStep{skip: true})
+	root.AddStep(&testStep{})
+	root.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		return errors.New("deployment failed")
+	}})
+	root.AddStep(&testStep{})
+
+	executor := DefaultExecutor()
+	executor.SetExecutable(root)
+	ctx := NewDefaultExecutionContext()
+	assert.NotNil(t, executor.Execute(ctx))
+
+	report := GetExecutionReportCollector(ctx).Report()
+	var paths, results []string
+	for _, step := range report.Steps {
+		paths = append(paths, step.Path)
+		results = append(results, step.Result)
+	}
+	assert.Equal(t, []string{
+		"DefaultCompound",
+		"DefaultCompound/rollbackableTestStep",
+		"DefaultCompound/testStep",
+		"DefaultCompound/testStep#1",
+	}, paths)
+	assert.Equal(t, []string{StepFailed, StepSkipped, StepExecuted, StepFailed}, results)
+	assert.Equal(t, "deployment failed", report.Steps[3].Message)
+	assert.False(t, report.Steps[3].EndTime.IsZero())
+}
+
+func TestMicroServiceCompoundScope(t *testing.T) {
+	newService := func(name string, deployType MicroServiceDeployType) *MicroServiceCompound {
+		service := &MicroServiceCompound{
+			ServiceName: name,
+			ExportVars:  []string{name + "Password"},
+			CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+				return deployType, nil
+			},
+		}
+		service.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			assert.Equal(t, "root", ctx.Get("rootVar"))
+			assert.Equal(t, deployType, GetCurrentDeployType(ctx))
+			ctx.Set("pvcNames", []string{name})
+			ctx.Set(name+"Password", name)
+			return nil
+		}})
+		return service
+	}
+	root := &ParallelCompound{}
+	root.AddStep(newService("first", CleanDeploy))
+	root.AddStep(newService("second", Update))
+
+	ctx := NewInitExecutionContext(map[string]interface{}{"rootVar": "root"})
+	assert.Nil(t, root.Execute(ctx))
+	assert.Nil(t, ctx.Get("pvcNames"))
+	assert.Nil(t, ctx.Get(ServiceDeployTypeKey.Name()))
+	assert.Equal(t, "first", ctx.Get("firstPassword"))
+	assert.Equal(t, "second", ctx.Get("secondPassword"))
+}
+
+func TestCancellation(t *testing.T) {
+	t.Run("Cancelled pipeline", func(t *testing.T) {
+		goCtx, cancel := context.WithCancel(context.Background())
+		executed := 0
+		root := &DefaultCompound{}
+		root.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			executed++
+			cancel()
+			return nil
+		}})
+		root.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			executed++
+			return nil
+		}})
+
+		ctx := NewInitExecutionContext(map[string]interface{}{GoContextKey.Name(): goCtx})
+		err := root.Execute(ctx)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("Step timeout", func(t *testing.T) {
+		step := WithTimeout(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			return sleepWithContext(GetContext(ctx), time.Minute)
+		}}, 10*time.Millisecond)
+
+		err := step.Execute(NewDefaultExecutionContext())
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, "testStep", GetStepName(step))
+	})
+
+	t.Run("Step timeout reaches the helper", func(t *testing.T) {
+		stored := &overriddenTestHelper{DefaultKubernetesHelperImpl{Client: fake.NewClientBuilder().Build()}}
+		ctx := NewDefaultExecutionContext()
+		KubernetesHelperKey.Set(ctx, stored)
+		start := time.Now()
+		step := WithTimeout(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			helper := GetKubernetesHelper(ctx)
+			assert.Equal(t, errOverridden, helper.WaitForDeploymentReady("deployment", "namespace", 60))
+			return helper.WaitForPVCBound("pvc", "namespace", 60)
+		}}, 10*time.Millisecond)
+
+		err := step.Execute(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Nil(t, stored.ctx)
+	})
+}
+
+var errOverridden = errors.New("overridden")
+
+type overriddenTestHelper struct {
+	DefaultKubernetesHelperImpl
+}
+
+func (r *overriddenTestHelper) WaitForDeploymentReady(deployName string, namespace string, waitSeconds int) error {
+	return errOverridden
+}
+
+func TestStepError(t *testing.T) {
+	service := &MicroServiceCompound{
+		ServiceName: "Fake",
+		CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+			return Update, nil
+		},
+	}
+	service.AddStep(&testStep{})
+	service.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		PanicError(NewNotFoundError("pvc is not found"), zap.NewNop().Error, "Failed to create PVC")
+		return nil
+	}})
+	root := &DefaultCompound{}
+	root.AddStep(service)
+
+	executor := DefaultExecutor()
+	executor.SetExecutable(root)
+	err := executor.Execute(NewDefaultExecutionContext())
+
+	var stepErr *StepError
+	assert.True(t, errors.As(err, &stepErr))
+	assert.Equal(t, "DefaultCompound/MicroServiceCompound/testStep#1", stepErr.Path)
+	assert.Contains(t, stepErr.Stack, "TestStepError")
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestStepInterceptors(t *testing.T) {
+	var calls []string
+	tracing := func(name string) StepInterceptor {
+		return func(step Executable, ctx ExecutionContext, next func(ctx ExecutionContext) error) error {
+			calls = append(calls, name+" before "+GetStepPath(ctx))
+			defer func() { calls = append(calls, name+" after "+GetStepPath(ctx)) }()
+			return next(ctx)
+		}
+	}
+	skipping := func(step Executable, ctx ExecutionContext, next func(ctx ExecutionContext) error) error {
+		if _, ok := step.(*rollbackableTestStep); ok {
+			return nil
+		}
+		return next(ctx)
+	}
+
+	nested := &DefaultCompound{}
+	nested.Use(tracing("nested"))
+	nested.AddStep(&testStep{})
+	root := &DefaultCompound{}
+	root.AddStep(nested)
+	root.AddStep(&rollbackableTestStep{})
+
+	executor := DefaultExecutor()
+	executor.Use(tracing("executor"), skipping)
+	executor.SetExecutable(root)
+	ctx := NewDefaultExecutionContext()
+	assert.Nil(t, executor.Execute(ctx))
+
+	assert.Equal(t, []string{
+		"executor before DefaultCompound/DefaultCompound",
+		"executor before DefaultCompound/DefaultCompound/testStep",
+		"nested before DefaultCompound/DefaultCompound/testStep",
+		"nested after DefaultCompound/DefaultCompound/testStep",
+		"executor after DefaultCompound/DefaultCompound/testStep",
+		"executor after DefaultCompound/DefaultCompound",
+		"executor before DefaultCompound/rollbackableTestStep",
+		"executor after DefaultCompound/rollbackableTestStep",
+	}, calls)
+	report := GetExecutionReportCollector(ctx).Report()
+	assert.Equal(t, StepSkipped, report.Steps[len(report.Steps)-1].Result)
+}
+
+func TestStepMetrics(t *testing.T) {
+	failures := testutil.ToFloat64(metrics.StepFailures.WithLabelValues("metricsTestStep"))
+
+	root := &DefaultCompound{}
+	root.AddStep(&metricsTestStep{})
+	executor := DefaultExecutor()
+	executor.SetExecutable(root)
+	assert.NotNil(t, executor.Execute(NewDefaultExecutionContext()))
+
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.StepFailures.WithLabelValues("metricsTestStep")))
+}
+
+type metricsTestStep struct {
+	DefaultExecutable
+}
+
+func (r *metricsTestStep) Execute(ctx ExecutionContext) error {
+	return errors.New("failed")
+}
+
+type validatingTestStep struct {
+	DefaultExecutable
+	validateFunc func(ctx ExecutionContext) error
+}
+
+func (r *validatingTestStep) Validate(ctx ExecutionContext) error {
+	return r.validateFunc(ctx)
+}
+
+func TestAggregatedValidation(t *testing.T) {
+	service := &MicroServiceCompound{
+		ServiceName: "Fake",
+		CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+			return Update, nil
+		},
+	}
+	service.AddStep(&validatingTestStep{validateFunc: func(ctx ExecutionContext) error {
+		return NewValidationError(NewFieldError("spec.storage.size", "should be set"), NewFieldError("spec.storage.volumes", "should be set"))
+	}})
+	root := &DefaultCompound{}
+	root.AddStep(&validatingTestStep{validateFunc: func(ctx ExecutionContext) error {
+		return errors.New("replicas should be positive")
+	}})
+	root.AddStep(service)
+	root.AddStep(&validatingTestStep{validateFunc: func(ctx ExecutionContext) error {
+		panic("unexpected")
+	}})
+
+	executor := DefaultExecutor()
+	executor.SetExecutable(root)
+	err := executor.Execute(NewDefaultExecutionContext())
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	var fields, steps []string
+	for _, fieldErr := range validationErr.Errors {
+		fields = append(fields, fieldErr.Field)
+		steps = append(steps, fieldErr.Step)
+	}
+	assert.Equal(t, []string{"", "spec.storage.size", "spec.storage.volumes", ""}, fields)
+	assert.Equal(t, []string{
+		"DefaultCompound/validatingTestStep",
+		"DefaultCompound/MicroServiceCompound/validatingTestStep",
+		"DefaultCompound/MicroServiceCompound/validatingTestStep",
+		"DefaultCompound/validatingTestStep#1",
+	}, steps)
+	assert.Contains(t, err.Error(), "spec.storage.size: should be set; spec.storage.volumes: should be set")
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Standard condition types of the CR status
+const (
+	ConditionReady       = "Ready"
+	ConditionProgressing = "Progressing"
+	ConditionDegraded    = "Degraded"
+	ConditionDRReady     = "DRReady"
+	ConditionPaused      = "Paused"
+	ConditionDrifted     = "Drifted"
+)
+
+const maxConditionMessageLength = 32768
+
+// ConditionsReconciler is an optional extension of CommonReconciler
+// which stores metav1.Condition compatible conditions and the observed generation in the CR status
+type ConditionsReconciler interface {
+	// GetConditions returns the conditions of the CR status to be modified in place
+	GetConditions() *[]metav1.Condition
+	UpdateObservedGeneration(generation int64)
+}
+
+// standardConditions maps the legacy status type to the standard conditions
+func standardConditions(statusType string, reason string, message string) []metav1.Condition {
+	condition := func(conditionType string, status metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, Reason: reason, Message: message}
+	}
+	switch statusType {
+	case "In Progress":
+		return []metav1.Condition{
+			condition(ConditionProgressing, metav1.ConditionTrue),
+			condition(ConditionReady, metav1.ConditionUnknown),
+		}
+	case "Successful":
+		return []metav1.Condition{
+			condition(ConditionReady, metav1.ConditionTrue),
+			condition(ConditionProgressing, metav1.ConditionFalse),
+			condition(ConditionDegraded, metav1.ConditionFalse),
+		}
+	case "Failed":
+		return []metav1.Condition{
+			condition(ConditionReady, metav1.ConditionFalse),
+			condition(ConditionProgressing, metav1.ConditionFalse),
+			condition(ConditionDegraded, metav1.ConditionTrue),
+		}
+	}
+	return nil
+}
+
+// drCondition maps the disaster recovery status to the DRReady condition, nil is returned for unknown statuses
+func drCondition(status string) *metav1.Condition {
+	condition := metav1.Condition{Type: ConditionDRReady}
+	switch status {
+	case "done":
+		condition.Status, condition.Reason = metav1.ConditionTrue, "DisasterRecoveryDone"
+	case "running":
+		condition.Status, condition.Reason = metav1.ConditionUnknown, "DisasterRecoveryRunning"
+	case "failed":
+		condition.Status, condition.Reason = metav1.ConditionFalse, "DisasterRecoveryFailed"
+	default:
+		return nil
+	}
+	return &condition
+}
+
+// setConditions sets the conditions with the generation of the CR, the transition time is changed only with the status
+func setConditions(reconciler ConditionsReconciler, generation int64, conditions ...metav1.Condition) {
+	current := reconciler.GetConditions()
+	if current == nil {
+		return
+	}
+	for _, condition := range conditions {
+		if condition.ObservedGeneration == 0 {
+			condition.ObservedGeneration = generation
+		}
+		if condition.Reason == "" {
+			condition.Reason = "Unknown"
+		}
+		// the message is limited by the condition schema
+		if len(condition.Message) > maxConditionMessageLength {
+			condition.Message = condition.Message[:maxConditionMessageLength]
+		}
+		meta.SetStatusCondition(current, condition)
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/consul"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/vault"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Key is a typed accessor of the execution context variable
+type Key[T any] struct {
+	name string
+}
+
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Lookup returns the variable and reports whether it is present and has the expected type
+func (k Key[T]) Lookup(ctx ExecutionContext) (T, bool) {
+	value, ok := ctx.Get(k.name).(T)
+	return value, ok
+}
+
+// Get returns the variable or the zero value if it is absent or has another type
+func (k Key[T]) Get(ctx ExecutionContext) T {
+	value, _ := k.Lookup(ctx)
+	return value
+}
+
+// MustGet returns the variable or panics with ContextVariableError
+func (k Key[T]) MustGet(ctx ExecutionContext) T {
+	value, err := k.Require(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Require returns the variable or ContextVariableError if it is absent or has another type
+func (k Key[T]) Require(ctx ExecutionContext) (T, error) {
+	raw := ctx.Get(k.name)
+	value, ok := raw.(T)
+	if !ok {
+		err := &ContextVariableError{
+			Key:      k.name,
+			Step:     GetStepPath(ctx),
+			Expected: reflect.TypeOf((*T)(nil)).Elem().String(),
+		}
+		if raw != nil {
+			err.Actual = reflect.TypeOf(raw).String()
+		}
+		return value, err
+	}
+	return value, nil
+}
+
+func (k Key[T]) Set(ctx ExecutionContext, value T) {
+	ctx.Set(k.name, value)
+}
+
+// ContextVariableError is returned when the requested variable is absent in the execution context or has another type
+type ContextVariableError struct {
+	Key      string
+	Step     string
+	Expected string
+	Actual   string
+}
+
+func (e *ContextVariableError) Error() string {
+	step := e.Step
+	if step == "" {
+		step = "<unknown>"
+	}
+	if e.Actual == "" {
+		return fmt.Sprintf("Variable %s of type %s is required by step %s, but is not found in execution context", e.Key, e.Expected, step)
+	}
+	return fmt.Sprintf("Variable %s is required by step %s to be of type %s, but has type %s", e.Key, step, e.Expected, e.Actual)
+}
+
+var (
+	SpecKey                       = NewKey[client.Object](constants.ContextSpec)
+	SpecHasChangesKey             = NewKey[bool](constants.ContextSpecHasChanges)
+	SchemaKey                     = NewKey[*runtime.Scheme](constants.ContextSchema)
+	RequestKey                    = NewKey[reconcile.Request](constants.ContextRequest)
+	ClientKey                     = NewKey[client.Client](constants.ContextClient)
+	KubeConfigKey                 = NewKey[*rest.Config](constants.ContextKubeClient)
+	LoggerKey                     = NewKey[*zap.Logger](constants.ContextLogger)
+	VaultKey                      = NewKey[vault.VaultHelper](constants.ContextVault)
+	ConsulKey                     = NewKey[consul.ConsulClient](constants.ContextConsul)
+	ConsulServiceRegistrationsKey = NewKey[map[string]*types.AgentServiceRegistration](constants.ContextConsulServiceRegistrations)
+	ConsulRegistrationKey         = NewKey[*types.ConsulRegistration](constants.ContextConsulRegistration)
+	HashConfigMapKey              = NewKey[string](constants.ContextHashConfigMap)
+	ServiceDeployTypeKey          = NewKey[MicroServiceDeployType](constants.ContextServiceDeployType)
+	ServiceDeploymentInfoKey      = NewKey[map[string]string](constants.ContextServiceDeploymentInfo)
+	KubernetesHelperKey           = NewKey[KubernetesHelper](constants.KubernetesHelperImpl)
+	StepPathKey                   = NewKey[string](constants.ContextStepPath)
+	DryRunKey                     = NewKey[bool](constants.ContextDryRun)
+	ExecutionPlanKey              = NewKey[*ExecutionPlan](constants.ContextExecutionPlan)
+	CheckpointStoreKey            = NewKey[CheckpointStore](constants.ContextCheckpointStore)
+	ExecutionReportKey            = NewKey[*ExecutionReportCollector](constants.ContextExecutionReport)
+	GoContextKey                  = NewKey[context.Context](constants.ContextGoContext)
+	EventEmitterKey               = NewKey[*EventEmitter](constants.ContextEventEmitter)
+	RetentionPoliciesKey          = NewKey[RetentionPolicies](constants.ContextRetentionPolicies)
+)
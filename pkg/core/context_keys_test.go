@@ -0,0 +1,31 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextKeys(t *testing.T) {
+	ctx := withStepPath(NewDefaultExecutionContext(), "Compound/Step")
+
+	HashConfigMapKey.Set(ctx, "spec-cm")
+	value, ok := HashConfigMapKey.Lookup(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "spec-cm", value)
+
+	_, ok = LoggerKey.Lookup(ctx)
+	assert.False(t, ok)
+	assert.Nil(t, LoggerKey.Get(ctx))
+
+	_, err := LoggerKey.Require(ctx)
+	var varErr *ContextVariableError
+	assert.True(t, errors.As(err, &varErr))
+	assert.Equal(t, "Variable contextLogger of type *zap.Logger is required by step Compound/Step, but is not found in execution context", err.Error())
+
+	ctx.Set(DryRunKey.Name(), "true")
+	assert.PanicsWithError(t, "Variable contextDryRun is required by step Compound/Step to be of type bool, but has type string", func() {
+		DryRunKey.MustGet(ctx)
+	})
+}
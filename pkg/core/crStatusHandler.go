@@ -2,17 +2,29 @@ package core
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	corev1 "k8s.io/api/core/v1"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type CRStatusHandler interface {
 	SetCRCondition(conditionStatus bool, statusType string, err error, reason string) CRStatusHandler
 	SetDRStatus(status string) CRStatusHandler
+	SetRollbackStatus(status types.RollbackStatus) CRStatusHandler
+	SetExecutionReport(report types.ExecutionReport) CRStatusHandler
+	SetNextRetryTime(nextRetry *v12.Time) CRStatusHandler
+	SetStandbyStatus(status types.StandbyStatus) CRStatusHandler
+	SetConditions(conditions ...v12.Condition) CRStatusHandler
+	SetObservedGeneration(generation int64) CRStatusHandler
+	SetDriftStatus(status types.DriftStatus) CRStatusHandler
 	Commit() error
 }
 
@@ -20,6 +32,26 @@ type DefaultCRStatusHandler struct {
 	CRStatusHandler
 	Reconciler CommonReconciler
 	KubeClient client.Client
+	// Events receives DR status transitions if set
+	Events *EventEmitter
+	// DREnabled sets the DRReady condition with the DR status, the services without DR don't get it
+	DREnabled bool
+	// changes are applied again to the latest CR if the status update conflicts
+	changes []func()
+}
+
+// apply changes the status and keeps the change for the conflict retries of Commit
+func (h DefaultCRStatusHandler) apply(change func()) DefaultCRStatusHandler {
+	change()
+	h.changes = append(h.changes[:len(h.changes):len(h.changes)], change)
+	return h
+}
+
+func (h DefaultCRStatusHandler) generation() int64 {
+	if instance := h.Reconciler.GetInstance(); isPersisted(instance) {
+		return instance.GetGeneration()
+	}
+	return 0
 }
 
 func (h DefaultCRStatusHandler) SetCRCondition(conditionStatus bool, statusType string, err error, reason string) CRStatusHandler {
@@ -37,8 +69,18 @@ func (h DefaultCRStatusHandler) SetCRCondition(conditionStatus bool, statusType
 		Reason:             reason,
 		Message:            strings.ReplaceAll(conditionMsg, "\t", " "),
 	}
-	h.Reconciler.UpdateStatus(condition)
-	return h
+	// the generation is read once, the conflict retries must not report the generation which was not reconciled
+	generation := h.generation()
+	return h.apply(func() {
+		h.Reconciler.UpdateStatus(condition)
+		// the standard conditions are kept in sync with the legacy one
+		if reconciler, ok := h.Reconciler.(ConditionsReconciler); ok {
+			setConditions(reconciler, generation, standardConditions(statusType, reason, condition.Message)...)
+			if statusType == "Successful" {
+				reconciler.UpdateObservedGeneration(generation)
+			}
+		}
+	})
 }
 
 func (h DefaultCRStatusHandler) SetDRStatus(status string) CRStatusHandler {
@@ -46,10 +88,142 @@ func (h DefaultCRStatusHandler) SetDRStatus(status string) CRStatusHandler {
 		Status: status,
 	}
 
-	h.Reconciler.UpdateDRStatus(drStatus)
+	generation := h.generation()
+	h = h.apply(func() {
+		h.Reconciler.UpdateDRStatus(drStatus)
+		if reconciler, ok := h.Reconciler.(ConditionsReconciler); ok && h.DREnabled {
+			if condition := drCondition(status); condition != nil {
+				setConditions(reconciler, generation, *condition)
+			}
+		}
+	})
+
+	eventType := corev1.EventTypeNormal
+	if status == "failed" {
+		eventType = corev1.EventTypeWarning
+	}
+	h.Events.Emit(h.Reconciler.GetInstance(), eventType, EventReasonDRStatusChanged, "Disaster recovery status is "+status)
+	return h
+}
+
+// SetRollbackStatus is applied only if the reconciler implements RollbackStatusReconciler
+func (h DefaultCRStatusHandler) SetRollbackStatus(status types.RollbackStatus) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(RollbackStatusReconciler); ok {
+		return h.apply(func() { reconciler.UpdateRollbackStatus(status) })
+	}
+	return h
+}
+
+// SetExecutionReport is applied only if the reconciler implements ExecutionReportReconciler
+func (h DefaultCRStatusHandler) SetExecutionReport(report types.ExecutionReport) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(ExecutionReportReconciler); ok {
+		return h.apply(func() { reconciler.UpdateExecutionReport(report) })
+	}
+	return h
+}
+
+// SetNextRetryTime is applied only if the reconciler implements RequeueStatusReconciler, nil clears the time
+func (h DefaultCRStatusHandler) SetNextRetryTime(nextRetry *v12.Time) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(RequeueStatusReconciler); ok {
+		return h.apply(func() { reconciler.UpdateNextRetryTime(nextRetry) })
+	}
 	return h
 }
 
+// SetStandbyStatus is applied only if the reconciler implements StandbyStatusReconciler
+func (h DefaultCRStatusHandler) SetStandbyStatus(status types.StandbyStatus) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(StandbyStatusReconciler); ok {
+		return h.apply(func() { reconciler.UpdateStandbyStatus(status) })
+	}
+	return h
+}
+
+// SetConditions is applied only if the reconciler implements ConditionsReconciler.
+// The observed generation of the conditions is the generation of the CR if not set.
+func (h DefaultCRStatusHandler) SetConditions(conditions ...v12.Condition) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(ConditionsReconciler); ok {
+		generation := h.generation()
+		return h.apply(func() { setConditions(reconciler, generation, conditions...) })
+	}
+	return h
+}
+
+// SetObservedGeneration is applied only if the reconciler implements ConditionsReconciler
+func (h DefaultCRStatusHandler) SetObservedGeneration(generation int64) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(ConditionsReconciler); ok {
+		return h.apply(func() { reconciler.UpdateObservedGeneration(generation) })
+	}
+	return h
+}
+
+// SetDriftStatus is applied only if the reconciler implements DriftStatusReconciler,
+// the Drifted condition is set if the reconciler implements ConditionsReconciler
+func (h DefaultCRStatusHandler) SetDriftStatus(status types.DriftStatus) CRStatusHandler {
+	if reconciler, ok := h.Reconciler.(DriftStatusReconciler); ok {
+		h = h.apply(func() { reconciler.UpdateDriftStatus(status) })
+	}
+	return h.SetConditions(driftCondition(status))
+}
+
+// Commit updates the CR status. On conflict the latest CR is read and the changes are applied to its status again,
+// the spec of the running cycle is kept.
 func (h DefaultCRStatusHandler) Commit() error {
-	return h.KubeClient.Status().Update(context.TODO(), h.Reconciler.GetInstance())
+	instance := h.Reconciler.GetInstance()
+	attempt := 0
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if attempt > 0 {
+			latest := instance.DeepCopyObject().(client.Object)
+			if err := h.KubeClient.Get(context.TODO(), client.ObjectKeyFromObject(instance), latest); err != nil {
+				return err
+			}
+			if err := refreshStatus(instance, latest); err != nil {
+				return err
+			}
+			for _, change := range h.changes {
+				change()
+			}
+		}
+		attempt++
+		// the response carries the latest spec, only the resource version is taken from it
+		committed := instance.DeepCopyObject().(client.Object)
+		if err := h.KubeClient.Status().Update(context.TODO(), committed); err != nil {
+			return err
+		}
+		instance.SetResourceVersion(committed.GetResourceVersion())
+		return nil
+	})
+}
+
+// refreshStatus replaces the status and the resource version of the instance with the ones of the latest CR,
+// the rest of the instance is left as the running cycle has read it
+func refreshStatus(instance client.Object, latest client.Object) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(instance)
+	if err != nil {
+		return err
+	}
+	latestContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(latest)
+	if err != nil {
+		return err
+	}
+	if status, ok := latestContent["status"]; ok {
+		content["status"] = status
+	} else {
+		delete(content, "status")
+	}
+	if err := unstructured.SetNestedField(content, latest.GetResourceVersion(), "metadata", "resourceVersion"); err != nil {
+		return err
+	}
+
+	if object, ok := instance.(runtime.Unstructured); ok {
+		object.SetUnstructuredContent(content)
+		return nil
+	}
+	// the object is decoded aside, so the instance is not left half updated on error
+	value := reflect.ValueOf(instance).Elem()
+	refreshed := reflect.New(value.Type())
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, refreshed.Interface()); err != nil {
+		return err
+	}
+	value.Set(refreshed.Elem())
+	return nil
 }
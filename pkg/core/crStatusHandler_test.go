@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type testConditionsReconciler struct {
+	CommonReconciler
+	instance           *v1.Pod
+	conditions         []metav1.Condition
+	observedGeneration int64
+}
+
+func (r *testConditionsReconciler) UpdateStatus(condition types.ServiceStatusCondition) {
+	r.instance.Status.Message = condition.Type
+}
+
+func (r *testConditionsReconciler) UpdateDRStatus(drStatus types.DisasterRecoveryStatus) {
+	r.instance.Status.Reason = drStatus.Status
+}
+
+func (r *testConditionsReconciler) GetInstance() client.Object {
+	return r.instance
+}
+
+func (r *testConditionsReconciler) GetConditions() *[]metav1.Condition {
+	return &r.conditions
+}
+
+func (r *testConditionsReconciler) UpdateObservedGeneration(generation int64) {
+	r.observedGeneration = generation
+}
+
+func TestCRStatusHandler(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "cr", Generation: 3}}
+	conflicts := 1
+	kubeClient := fake.NewClientBuilder().
+		WithObjects(pod).
+		WithStatusSubresource(pod).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if conflicts > 0 {
+					conflicts--
+					return k8sErrors.NewConflict(schema.GroupResource{Resource: "pods"}, obj.GetName(), nil)
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	instance := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), instance))
+	reconciler := &testConditionsReconciler{instance: instance}
+	handler := DefaultCRStatusHandler{Reconciler: reconciler, KubeClient: kubeClient, DREnabled: true}
+
+	assert.Nil(t, handler.SetCRCondition(true, "In Progress", nil, "ReconcileCycleInProgress").SetDRStatus("running").Commit())
+	assert.Equal(t, 0, conflicts)
+	progressing := meta.FindStatusCondition(reconciler.conditions, ConditionProgressing)
+	assert.Equal(t, metav1.ConditionTrue, progressing.Status)
+	assert.Equal(t, int64(3), progressing.ObservedGeneration)
+	assert.Equal(t, metav1.ConditionUnknown, meta.FindStatusCondition(reconciler.conditions, ConditionDRReady).Status)
+
+	stored := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+	assert.Equal(t, "In Progress", stored.Status.Message)
+	assert.Equal(t, "running", stored.Status.Reason)
+
+	assert.Nil(t, handler.SetCRCondition(true, "Successful", nil, "ReconcileCycleSucceeded").Commit())
+	ready := meta.FindStatusCondition(reconciler.conditions, ConditionReady)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, metav1.ConditionFalse, meta.FindStatusCondition(reconciler.conditions, ConditionDegraded).Status)
+	assert.Equal(t, int64(3), reconciler.observedGeneration)
+
+	// transition time is kept if the status is the same
+	transitionTime := ready.LastTransitionTime
+	assert.Nil(t, handler.SetConditions(metav1.Condition{Type: ConditionReady, Status: metav1.ConditionTrue, Reason: "Checked"}).Commit())
+	assert.Equal(t, transitionTime, meta.FindStatusCondition(reconciler.conditions, ConditionReady).LastTransitionTime)
+	assert.Equal(t, "Checked", meta.FindStatusCondition(reconciler.conditions, ConditionReady).Reason)
+}
+
+func TestCRStatusHandlerGenerationOnConflict(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "cr", Generation: 3}}
+	conflicts := 1
+	kubeClient := fake.NewClientBuilder().
+		WithObjects(pod).
+		WithStatusSubresource(pod).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if conflicts > 0 {
+					conflicts--
+					// the spec is changed while the reconciled generation is committed
+					latest := &v1.Pod{}
+					if err := c.Get(ctx, client.ObjectKeyFromObject(obj), latest); err != nil {
+						return err
+					}
+					latest.Generation++
+					latest.Spec.NodeName = "changed"
+					if err := c.Update(ctx, latest); err != nil {
+						return err
+					}
+					return k8sErrors.NewConflict(schema.GroupResource{Resource: "pods"}, obj.GetName(), nil)
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	instance := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), instance))
+	reconciler := &testConditionsReconciler{instance: instance}
+	handler := DefaultCRStatusHandler{Reconciler: reconciler, KubeClient: kubeClient}
+
+	assert.Nil(t, handler.SetCRCondition(true, "Successful", nil, "ReconcileCycleSucceeded").SetDRStatus("done").
+		SetConditions(metav1.Condition{Type: ConditionDrifted, Status: metav1.ConditionFalse, Reason: "NoDrift"}).Commit())
+	assert.Equal(t, 0, conflicts)
+	assert.Equal(t, int64(3), reconciler.observedGeneration)
+	for _, condition := range reconciler.conditions {
+		assert.Equal(t, int64(3), condition.ObservedGeneration, condition.Type)
+	}
+	// DR is not configured for the handler
+	assert.Nil(t, meta.FindStatusCondition(reconciler.conditions, ConditionDRReady))
+
+	// The running cycle keeps the spec it has read, only the status is committed to the latest CR
+	assert.Equal(t, int64(3), instance.Generation)
+	assert.Equal(t, "", instance.Spec.NodeName)
+	stored := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+	assert.Equal(t, int64(4), stored.Generation)
+	assert.Equal(t, "changed", stored.Spec.NodeName)
+	assert.Equal(t, "Successful", stored.Status.Message)
+	assert.Equal(t, "done", stored.Status.Reason)
+}
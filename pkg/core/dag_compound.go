@@ -0,0 +1,212 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DAGCompound runs its steps according to the dependencies declared by DependentExecutable steps.
+// A step is started as soon as all steps producing its required variables are finished.
+// Steps producing the same variable are executed in the order they were added.
+// Steps without declarations have no ordering constraints.
+// With RollbackOnFailure the steps completed before the failure are compensated in reverse order of their completion,
+// so dependent steps are compensated before their dependencies.
+type DAGCompound struct {
+	DefaultCompound
+	// MaxWorkers limits the number of steps running at the same time. Zero means no limit
+	MaxWorkers int
+}
+
+type stepGraph struct {
+	steps        []Executable
+	dependents   [][]int
+	dependencies []int
+	order        []int
+}
+
+func (r *DAGCompound) Validate(ctx ExecutionContext) error {
+	if _, err := r.buildGraph(ctx); err != nil {
+		return err
+	}
+	return r.DefaultCompound.Validate(ctx)
+}
+
+func (r *DAGCompound) Execute(ctx ExecutionContext) error {
+	ctx = withInterceptors(ctx, r.interceptors)
+	graph, err := r.buildGraph(ctx)
+	if err != nil {
+		return err
+	}
+	segments := stepPathSegments(r.executableSteps)
+	run := func(stepFunc func(stepCtx ExecutionContext, step Executable) error) error {
+		return graph.run(r.MaxWorkers, func(index int, element Executable) error {
+			return stepFunc(withStepPath(ctx, segments[index]), element)
+		})
+	}
+	if r.RollbackOnFailure || isRollbackTracked(ctx) {
+		return r.executeWithRollback(ctx, run)
+	}
+	return run(executeStep)
+}
+
+func (r *DAGCompound) buildGraph(ctx ExecutionContext) (*stepGraph, error) {
+	graph := &stepGraph{
+		steps:        r.executableSteps,
+		dependents:   make([][]int, len(r.executableSteps)),
+		dependencies: make([]int, len(r.executableSteps)),
+	}
+	edges := map[[2]int]bool{}
+	addEdge := func(from, to int) {
+		if from == to || edges[[2]int{from, to}] {
+			return
+		}
+		edges[[2]int{from, to}] = true
+		graph.dependents[from] = append(graph.dependents[from], to)
+		graph.dependencies[to]++
+	}
+
+	producers := map[string][]int{}
+	var producedVars []string
+	for i, element := range r.executableSteps {
+		for _, key := range producesOf(element) {
+			if _, ok := producers[key]; !ok {
+				producedVars = append(producedVars, key)
+			}
+			producers[key] = append(producers[key], i)
+		}
+	}
+	for _, key := range producedVars {
+		indexes := producers[key]
+		for j := 1; j < len(indexes); j++ {
+			addEdge(indexes[j-1], indexes[j])
+		}
+	}
+
+	var errs []error
+	for i, element := range r.executableSteps {
+		for _, key := range requiresOf(element) {
+			if indexes, ok := producers[key]; ok {
+				for _, producer := range indexes {
+					addEdge(producer, i)
+				}
+			} else if ctx.Get(key) == nil {
+				errs = append(errs, &ExecutionError{Msg: fmt.Sprintf(
+					"Step %s requires %s variable which is neither produced by any step nor present in the execution context",
+					GetStepName(element), key)})
+			}
+		}
+	}
+
+	if cycle := graph.sort(); len(cycle) > 0 {
+		var names []string
+		for _, index := range cycle {
+			names = append(names, GetStepName(r.executableSteps[index]))
+		}
+		errs = append(errs, &ExecutionError{Msg: "Dependency cycle is detected between steps: " + strings.Join(names, ", ")})
+	}
+
+	return graph, NewAggregatedError(errs)
+}
+
+// sort fills topological order of the steps and returns the steps which could not be ordered because of a cycle
+func (g *stepGraph) sort() []int {
+	remaining := make([]int, len(g.dependencies))
+	copy(remaining, g.dependencies)
+
+	g.order = nil
+	var ready []int
+	for i, count := range remaining {
+		if count == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		current := ready[0]
+		ready = ready[1:]
+		g.order = append(g.order, current)
+		for _, dependent := range g.dependents[current] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	var cycle []int
+	for i, count := range remaining {
+		if count > 0 {
+			cycle = append(cycle, i)
+		}
+	}
+	return cycle
+}
+
+// run executes ready steps concurrently. After the first failure no new steps are started.
+func (g *stepGraph) run(maxWorkers int, stepFunc func(index int, step Executable) error) error {
+	if maxWorkers <= 0 || maxWorkers > len(g.steps) {
+		maxWorkers = len(g.steps)
+	}
+
+	type stepResult struct {
+		index int
+		err   error
+	}
+
+	remaining := make([]int, len(g.dependencies))
+	copy(remaining, g.dependencies)
+	var ready []int
+	for i, count := range remaining {
+		if count == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	results := make(chan stepResult)
+	running := 0
+	var errs []error
+	for len(ready) > 0 || running > 0 {
+		for len(errs) == 0 && len(ready) > 0 && running < maxWorkers {
+			index := ready[0]
+			ready = ready[1:]
+			running++
+			go func(index int) {
+				results <- stepResult{
+					index: index,
+					err:   callRecovered(func() error { return stepFunc(index, g.steps[index]) }),
+				}
+			}(index)
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		for _, dependent := range g.dependents[result.index] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	return NewAggregatedError(errs)
+}
+
+func requiresOf(element Executable) []string {
+	if dependent, ok := UnwrapStep(element).(DependentExecutable); ok {
+		return dependent.Requires()
+	}
+	return nil
+}
+
+func producesOf(element Executable) []string {
+	if dependent, ok := UnwrapStep(element).(DependentExecutable); ok {
+		return dependent.Produces()
+	}
+	return nil
+}
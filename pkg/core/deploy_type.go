@@ -0,0 +1,216 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	v14 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DeployTypeAware is implemented by steps which run only in the listed deploy types of the current service.
+// Other steps run in any deploy type. The step is skipped before its Condition is evaluated.
+type DeployTypeAware interface {
+	DeployTypes() []MicroServiceDeployType
+}
+
+// DeployTypeStep is a decorator which limits the wrapped step to the deploy types
+type DeployTypeStep struct {
+	Executable
+	Types []MicroServiceDeployType
+}
+
+// RunIn limits the step to the deploy types, e.g. RunIn(step, CleanDeploy, CleanupRequired)
+func RunIn(step Executable, deployTypes ...MicroServiceDeployType) *DeployTypeStep {
+	return &DeployTypeStep{Executable: step, Types: deployTypes}
+}
+
+func (r *DeployTypeStep) DeployTypes() []MicroServiceDeployType {
+	return r.Types
+}
+
+func (r *DeployTypeStep) Unwrap() Executable {
+	return r.Executable
+}
+
+// runsInDeployType checks every DeployTypeAware of the decorators chain against the current deploy type
+func runsInDeployType(ctx ExecutionContext, element Executable) bool {
+	// the key is read directly, GetCurrentDeployType stores the empty value in the context
+	current := ServiceDeployTypeKey.Get(ctx)
+	for step := element; step != nil; {
+		if aware, ok := step.(DeployTypeAware); ok && !containsDeployType(aware.DeployTypes(), current) {
+			return false
+		}
+		wrapper, ok := step.(StepWrapper)
+		if !ok {
+			break
+		}
+		step = wrapper.Unwrap()
+	}
+	return true
+}
+
+func containsDeployType(deployTypes []MicroServiceDeployType, deployType MicroServiceDeployType) bool {
+	for _, t := range deployTypes {
+		if t == deployType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeploymentState is the observed and the desired state of the service used to detect its deploy type
+type DeploymentState struct {
+	// Exists reports whether the workloads of the service are present in the cluster
+	Exists bool
+	// Deleting reports whether the CR is being deleted
+	Deleting bool
+	// LastDeploymentFailed reports whether the previous deployment of the service has not succeeded
+	LastDeploymentFailed bool
+	CurrentImage         string
+	DesiredImage         string
+	CurrentReplicas      int
+	DesiredReplicas      int
+}
+
+// DetectDeployType returns the deploy type for the state. Version changes have priority over scaling,
+// other changes of the existing service are Update.
+func DetectDeployType(state DeploymentState) MicroServiceDeployType {
+	switch {
+	case state.Deleting:
+		return Uninstall
+	case !state.Exists:
+		return CleanDeploy
+	case state.LastDeploymentFailed:
+		return CleanupRequired
+	}
+
+	if state.CurrentImage != "" && state.DesiredImage != "" && state.CurrentImage != state.DesiredImage {
+		if CompareVersions(imageTag(state.CurrentImage), imageTag(state.DesiredImage)) > 0 {
+			return Downgrade
+		}
+		return Upgrade
+	}
+
+	if state.DesiredReplicas > 0 && state.CurrentReplicas > 0 {
+		if state.DesiredReplicas > state.CurrentReplicas {
+			return ScaleOut
+		} else if state.DesiredReplicas < state.CurrentReplicas {
+			return ScaleIn
+		}
+	}
+	return Update
+}
+
+// DetectServiceDeployType fills the observed part of the state from the cluster: deployments and stateful sets
+// found by the label selector, the deletion timestamp of the CR and the result of the previous deployment.
+// It can be used as MicroServiceCompound.CalcDeployType.
+func DetectServiceDeployType(ctx ExecutionContext, serviceName string, labelSelector map[string]string,
+	desiredImage string, desiredReplicas int) (MicroServiceDeployType, error) {
+	request := RequestKey.MustGet(ctx)
+	helperImpl := GetKubernetesHelper(ctx)
+
+	state := DeploymentState{
+		DesiredImage:    desiredImage,
+		DesiredReplicas: desiredReplicas,
+	}
+	if spec, ok := SpecKey.Lookup(ctx); ok && spec != nil {
+		state.Deleting = spec.GetDeletionTimestamp() != nil
+	}
+	if info, ok := ServiceDeploymentInfoKey.Lookup(ctx); ok {
+		result, deployed := info[serviceName]
+		state.LastDeploymentFailed = deployed && result != "" && result != constants.MicroServiceSuccessDeploymentResult
+	}
+
+	deployments := &v14.DeploymentList{}
+	if err := helperImpl.ListRuntimeObjectsByLabels(deployments, request.Namespace, labelSelector); err != nil {
+		return Empty, err
+	}
+	for _, deployment := range deployments.Items {
+		state.observe(deployment.Spec.Replicas, deployment.Spec.Template.Spec.Containers)
+	}
+
+	statefulSets := &v14.StatefulSetList{}
+	if err := helperImpl.ListRuntimeObjectsByLabels(statefulSets, request.Namespace, labelSelector); err != nil {
+		return Empty, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		state.observe(statefulSet.Spec.Replicas, statefulSet.Spec.Template.Spec.Containers)
+	}
+
+	result := DetectDeployType(state)
+	if log, ok := LoggerKey.Lookup(ctx); ok {
+		log.Debug(fmt.Sprintf("%s deploy mode is used for %s service", result, serviceName))
+	}
+	return result, nil
+}
+
+func (s *DeploymentState) observe(replicas *int32, containers []v1.Container) {
+	s.Exists = true
+	if replicas != nil {
+		s.CurrentReplicas += int(*replicas)
+	} else {
+		s.CurrentReplicas++
+	}
+	if s.CurrentImage == "" && len(containers) > 0 {
+		s.CurrentImage = containers[0].Image
+	}
+}
+
+// imageTag returns the tag of the image reference or the reference itself if there is no tag
+func imageTag(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		return image[colon+1:]
+	}
+	return image
+}
+
+// CompareVersions compares dot separated versions like v1.2.10 numerically segment by segment,
+// non-numeric segments are compared as strings. It returns -1, 0 or 1.
+func CompareVersions(a string, b string) int {
+	aSegments := strings.FieldsFunc(strings.TrimPrefix(a, "v"), isVersionSeparator)
+	bSegments := strings.FieldsFunc(strings.TrimPrefix(b, "v"), isVersionSeparator)
+	for i := 0; i < len(aSegments) || i < len(bSegments); i++ {
+		var aSegment, bSegment string
+		if i < len(aSegments) {
+			aSegment = aSegments[i]
+		}
+		if i < len(bSegments) {
+			bSegment = bSegments[i]
+		}
+		if result := compareVersionSegments(aSegment, bSegment); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '_'
+}
+
+func compareVersionSegments(a string, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+	if a == "" {
+		aNumber, aErr = 0, nil
+	}
+	if b == "" {
+		bNumber, bErr = 0, nil
+	}
+	if aErr == nil && bErr == nil {
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDeployType(t *testing.T) {
+	existing := DeploymentState{Exists: true, CurrentImage: "repo/mongo:5.0.9", DesiredImage: "repo/mongo:5.0.9",
+		CurrentReplicas: 3, DesiredReplicas: 3}
+	with := func(change func(state *DeploymentState)) DeploymentState {
+		state := existing
+		change(&state)
+		return state
+	}
+
+	assert.Equal(t, Update, DetectDeployType(existing))
+	assert.Equal(t, CleanDeploy, DetectDeployType(DeploymentState{DesiredImage: "repo/mongo:5.0.9"}))
+	assert.Equal(t, Uninstall, DetectDeployType(with(func(s *DeploymentState) { s.Deleting = true })))
+	assert.Equal(t, CleanupRequired, DetectDeployType(with(func(s *DeploymentState) { s.LastDeploymentFailed = true })))
+	assert.Equal(t, Upgrade, DetectDeployType(with(func(s *DeploymentState) { s.DesiredImage = "repo/mongo:5.0.10" })))
+	assert.Equal(t, Downgrade, DetectDeployType(with(func(s *DeploymentState) { s.DesiredImage = "repo/mongo:4.4" })))
+	assert.Equal(t, ScaleOut, DetectDeployType(with(func(s *DeploymentState) { s.DesiredReplicas = 5 })))
+	assert.Equal(t, ScaleIn, DetectDeployType(with(func(s *DeploymentState) { s.DesiredReplicas = 1 })))
+
+	assert.Equal(t, -1, CompareVersions("v1.2.9", "1.2.10"))
+	assert.Equal(t, 0, CompareVersions("1.2", "1.2.0"))
+	assert.Equal(t, "5.0.9", imageTag("registry:5000/repo/mongo:5.0.9@sha256:abc"))
+}
+
+func TestRunIn(t *testing.T) {
+	var executed []string
+	newStep := func(name string) Executable {
+		return &testStep{executeFunc: func(ctx ExecutionContext) error {
+			executed = append(executed, name)
+			return nil
+		}}
+	}
+	service := &MicroServiceCompound{
+		ServiceName: "mongo",
+		CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+			return Upgrade, nil
+		},
+	}
+	service.AddStep(RunIn(newStep("install"), CleanDeploy))
+	service.AddStep(RunIn(WithRetry(newStep("upgrade"), RetryPolicy{}), Upgrade, Downgrade))
+	service.AddStep(newStep("always"))
+
+	ctx := NewDefaultExecutionContext()
+	assert.Nil(t, service.Execute(ctx))
+	assert.Equal(t, []string{"upgrade", "always"}, executed)
+
+	plan := &ExecutionPlan{}
+	assert.Nil(t, service.Plan(ctx, plan))
+	assert.Equal(t, PlanSkip, plan.Entries[0].Action)
+	assert.Equal(t, PlanRun, plan.Entries[1].Action)
+}
+
+func TestGetMicroServiceDeployType(t *testing.T) {
+	ctx := NewDefaultExecutionContext()
+	assert.Equal(t, CleanDeploy, GetMicroServiceDeployType(ctx, "mongo"))
+	assert.Equal(t, CleanDeploy, GetMicroServiceDeployTypeWithCleanup(ctx, "mongo"))
+
+	AddServiceDeployResultToContext(ctx, "mongo", "failed")
+	AddServiceDeployResultToContext(ctx, "backup", constants.MicroServiceSuccessDeploymentResult)
+	// The failed deployment is cleaned up only when it is asked for
+	assert.Equal(t, CleanDeploy, GetMicroServiceDeployType(ctx, "mongo"))
+	assert.Equal(t, CleanupRequired, GetMicroServiceDeployTypeWithCleanup(ctx, "mongo"))
+	assert.Equal(t, Update, GetMicroServiceDeployTypeWithCleanup(ctx, "backup"))
+	assert.Equal(t, CleanDeploy, GetMicroServiceDeployTypeWithCleanup(ctx, "arbiter"))
+}
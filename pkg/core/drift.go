@@ -0,0 +1,455 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	DriftReasonMissing  = "Missing"
+	DriftReasonModified = "Modified"
+)
+
+// DriftFieldOwner is the field manager of the server-side apply used to check and to remediate the drift
+const DriftFieldOwner = "nosqldb-operator-drift"
+
+// DesiredObjectsBuilder returns the objects which the CR is expected to have in the cluster
+type DesiredObjectsBuilder interface {
+	BuildDesiredObjects(ctx ExecutionContext) ([]client.Object, error)
+}
+
+// DriftDetection compares the desired objects with the live ones on the reconciles without spec changes
+type DriftDetection struct {
+	Builder DesiredObjectsBuilder
+	// Interval of the checks, the CR is requeued with it if the resync interval of RequeuePolicy is longer or not set
+	Interval time.Duration
+	// Remediate re-applies the drifted objects
+	Remediate bool
+	// IgnoredFields are dot separated paths excluded from the comparison, e.g. "spec.replicas"
+	IgnoredFields []string
+}
+
+// DetectDrift compares the desired object with the live one. Only the fields set in the desired object are compared,
+// so the other server-populated fields are ignored. Nil values, empty strings, maps and lists are treated as not set.
+// The desired fields are compared with the values the API server stores for them, which are read with the dry-run
+// server-side apply, so the defaults like the target port of the service don't drift. If the dry run fails,
+// the zero numbers are treated as not set as well. False is always compared.
+// Nil is returned if the object has not drifted.
+func DetectDrift(ctx context.Context, cl client.Client, scheme *runtime.Scheme, desired client.Object, ignoredFields []string) (*types.DriftedObject, error) {
+	drifted := &types.DriftedObject{Kind: objectKind(desired, scheme), Name: desired.GetName()}
+
+	live := newObjectOf(desired)
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(desired), live); err != nil {
+		if k8sErrors.IsNotFound(err) {
+			drifted.Reason = DriftReasonMissing
+			return drifted, nil
+		}
+		return nil, err
+	}
+
+	desiredFields, err := comparableFields(desired, ignoredFields)
+	if err != nil {
+		return nil, err
+	}
+	expectedFields, err := dryRunFields(ctx, cl, scheme, desired, desiredFields)
+	if err != nil {
+		expectedFields = pruneZero(desiredFields)
+	}
+	liveFields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted.Fields = diffFields("", expectedFields, liveFields)
+	if len(drifted.Fields) == 0 {
+		return nil, nil
+	}
+	drifted.Reason = DriftReasonModified
+	return drifted, nil
+}
+
+// RemediateDrift creates the missing object or applies the desired fields to the live one with the server-side apply,
+// so the lists are merged by their keys and the fields which are not desired are kept
+func RemediateDrift(ctx context.Context, cl client.Client, scheme *runtime.Scheme, desired client.Object, drifted *types.DriftedObject, ignoredFields []string) error {
+	if drifted.Reason == DriftReasonMissing {
+		return cl.Create(ctx, desired.DeepCopyObject().(client.Object))
+	}
+
+	desiredFields, err := comparableFields(desired, ignoredFields)
+	if err != nil {
+		return err
+	}
+	applied, err := applyConfiguration(desired, scheme, pruneZero(desiredFields))
+	if err != nil {
+		return err
+	}
+	return cl.Patch(ctx, applied, client.Apply, client.FieldOwner(DriftFieldOwner), client.ForceOwnership)
+}
+
+// dryRunFields returns the desired fields with the values the API server would store for them,
+// the fields which are not stored are left out
+func dryRunFields(ctx context.Context, cl client.Client, scheme *runtime.Scheme, desired client.Object, desiredFields map[string]interface{}) (map[string]interface{}, error) {
+	applied, err := applyConfiguration(desired, scheme, pruneZero(desiredFields))
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.Patch(ctx, applied, client.Apply, client.DryRunAll, client.FieldOwner(DriftFieldOwner), client.ForceOwnership); err != nil {
+		return nil, err
+	}
+	selected, _ := selectFields(desiredFields, applied.Object).(map[string]interface{})
+	if selected == nil {
+		selected = map[string]interface{}{}
+	}
+	return selected, nil
+}
+
+// applyConfiguration returns the server-side apply configuration of the object with the fields
+func applyConfiguration(object client.Object, scheme *runtime.Scheme, fields map[string]interface{}) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(object, scheme)
+	if err != nil {
+		return nil, err
+	}
+	applied := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(fields)}
+	if applied.Object == nil {
+		applied.Object = map[string]interface{}{}
+	}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(object.GetNamespace())
+	applied.SetName(object.GetName())
+	return applied, nil
+}
+
+// selectFields returns the values of the source at the paths set in the fields, nil is returned if there is none
+func selectFields(fields interface{}, source interface{}) interface{} {
+	switch v := fields.(type) {
+	case map[string]interface{}:
+		sourceValue, _ := source.(map[string]interface{})
+		result := map[string]interface{}{}
+		for key, item := range v {
+			if selected := selectFields(item, sourceValue[key]); selected != nil {
+				result[key] = selected
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	case []interface{}:
+		sourceValue, _ := source.([]interface{})
+		if len(sourceValue) != len(v) {
+			// the list is compared as the desired one, diffFields reports the length mismatch
+			return v
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = selectFields(item, sourceValue[i])
+		}
+		return result
+	case nil:
+		return nil
+	}
+	return source
+}
+
+func newObjectOf(object client.Object) client.Object {
+	return reflect.New(reflect.TypeOf(object).Elem()).Interface().(client.Object)
+}
+
+func objectKind(object client.Object, scheme *runtime.Scheme) string {
+	if scheme != nil {
+		if gvk, err := apiutil.GVKForObject(object, scheme); err == nil {
+			return gvk.Kind
+		}
+	}
+	return reflect.TypeOf(object).Elem().Name()
+}
+
+// comparableFields returns the fields of the object without the status and the server-populated metadata.
+// The write-only stringData of the secret is moved to its data as the API server does it.
+func comparableFields(object client.Object, ignoredFields []string) (map[string]interface{}, error) {
+	if secret, ok := object.(*corev1.Secret); ok && len(secret.StringData) > 0 {
+		secret = secret.DeepCopy()
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for key, value := range secret.StringData {
+			secret.Data[key] = []byte(value)
+		}
+		secret.StringData = nil
+		object = secret
+	}
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "status")
+	metadata, _ := fields["metadata"].(map[string]interface{})
+	fields["metadata"] = map[string]interface{}{
+		"labels":      metadata["labels"],
+		"annotations": metadata["annotations"],
+	}
+	for _, field := range ignoredFields {
+		unstructured.RemoveNestedField(fields, strings.Split(field, ".")...)
+	}
+	pruned, _ := pruneEmpty(fields).(map[string]interface{})
+	if pruned == nil {
+		pruned = map[string]interface{}{}
+	}
+	return pruned, nil
+}
+
+// pruneEmpty removes nil values, empty strings, maps and lists, nil is returned if nothing is left
+func pruneEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, item := range v {
+			if pruned := pruneEmpty(item); pruned != nil {
+				result[key] = pruned
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = pruneEmpty(item)
+		}
+		return result
+	case string:
+		if v == "" {
+			return nil
+		}
+	}
+	return value
+}
+
+// pruneZero removes the zero numbers which the not set fields without omitempty are converted to
+func pruneZero(fields map[string]interface{}) map[string]interface{} {
+	pruned, _ := pruneZeroValue(fields).(map[string]interface{})
+	if pruned == nil {
+		pruned = map[string]interface{}{}
+	}
+	return pruned
+}
+
+// pruneZeroValue removes the zero numbers and the maps left empty, the list items are kept in place as nil
+func pruneZeroValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, item := range v {
+			if pruned := pruneZeroValue(item); pruned != nil {
+				result[key] = pruned
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = pruneZeroValue(item)
+		}
+		return result
+	case int64:
+		if v == 0 {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	}
+	return value
+}
+
+// diffFields returns the paths of the desired fields which differ from the live ones
+func diffFields(path string, desired interface{}, live interface{}) []string {
+	switch desiredValue := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		keys := make([]string, 0, len(desiredValue))
+		for key := range desiredValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var fields []string
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			fields = append(fields, diffFields(fieldPath, desiredValue[key], liveValue[key])...)
+		}
+		return fields
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(liveValue) != len(desiredValue) {
+			return []string{path}
+		}
+		var fields []string
+		for i := range desiredValue {
+			fields = append(fields, diffFields(fmt.Sprintf("%s[%d]", path, i), desiredValue[i], liveValue[i])...)
+		}
+		return fields
+	}
+	if !reflect.DeepEqual(desired, live) {
+		return []string{path}
+	}
+	return nil
+}
+
+func describeDrift(objects []types.DriftedObject) string {
+	descriptions := make([]string, 0, len(objects))
+	for _, object := range objects {
+		description := fmt.Sprintf("%s %s is %s", object.Kind, object.Name, strings.ToLower(object.Reason))
+		if len(object.Fields) > 0 {
+			description += " (" + strings.Join(object.Fields, ", ") + ")"
+		}
+		descriptions = append(descriptions, description)
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// checkDrift compares the desired objects with the live ones and reports the drift.
+// Failures of the check are logged only, they don't fail the reconcile.
+func (r *ReconcileCommonService) checkDrift(ctx ExecutionContext, crHandler CRStatusHandler, request reconcile.Request, logger *zap.Logger) {
+	detection := r.DriftDetection
+	desired, err := detection.Builder.BuildDesiredObjects(ctx)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to build desired objects for drift check, err: %v", err))
+		return
+	}
+
+	goCtx := GetContext(ctx)
+	status := types.DriftStatus{LastCheckTime: metav1.Now()}
+	var driftedObjects []client.Object
+	for _, object := range desired {
+		drifted, err := DetectDrift(goCtx, r.Client, r.Scheme, object, detection.IgnoredFields)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to check drift of %s, err: %v", object.GetName(), err))
+			continue
+		}
+		if drifted != nil {
+			status.Objects = append(status.Objects, *drifted)
+			driftedObjects = append(driftedObjects, object)
+		}
+	}
+	status.Drifted = len(status.Objects) > 0
+	metrics.DriftedObjects.WithLabelValues(request.Namespace, request.Name).Set(float64(len(status.Objects)))
+
+	if status.Drifted {
+		status.Message = describeDrift(status.Objects)
+		logger.Warn("Drift from the desired state is detected: " + status.Message)
+		EmitWarningEvent(ctx, EventReasonDriftDetected, status.Message)
+
+		if detection.Remediate {
+			status.Remediated = true
+			for i, object := range driftedObjects {
+				if err := RemediateDrift(goCtx, r.Client, r.Scheme, object, &status.Objects[i], detection.IgnoredFields); err != nil {
+					logger.Warn(fmt.Sprintf("Failed to re-apply %s, err: %v", object.GetName(), err))
+					status.Remediated = false
+				}
+			}
+			if status.Remediated {
+				EmitNormalEvent(ctx, EventReasonDriftRemediated, "Drifted objects are re-applied")
+			}
+		}
+	}
+
+	// the status is committed on changes only, otherwise every check would trigger the next reconcile
+	if !r.isDriftChanged(request, status) {
+		return
+	}
+	if !status.Drifted {
+		logger.Info("Drift is resolved")
+		EmitNormalEvent(ctx, EventReasonDriftResolved, "Live objects match the desired state")
+	}
+	if err := crHandler.SetDriftStatus(status).Commit(); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to update drift status, err: %v", err))
+	}
+}
+
+// isDriftChanged reports whether the drift differs from the Drifted condition of the CR, e.g. after the operator restart.
+// The drift is kept in memory only if the reconciler doesn't store the conditions.
+func (r *ReconcileCommonService) isDriftChanged(request reconcile.Request, status types.DriftStatus) bool {
+	reconciler, ok := r.Reconciler.(ConditionsReconciler)
+	if !ok || reconciler.GetConditions() == nil {
+		return r.setDrift(request, driftFingerprint(status))
+	}
+	condition := meta.FindStatusCondition(*reconciler.GetConditions(), ConditionDrifted)
+	if condition == nil {
+		return status.Drifted
+	}
+	expected := driftCondition(status)
+	// the stored message is limited by the condition schema
+	if len(expected.Message) > maxConditionMessageLength {
+		expected.Message = expected.Message[:maxConditionMessageLength]
+	}
+	return condition.Status != expected.Status || condition.Reason != expected.Reason || condition.Message != expected.Message
+}
+
+// driftCondition returns the Drifted condition reporting the drift status
+func driftCondition(status types.DriftStatus) metav1.Condition {
+	condition := metav1.Condition{Type: ConditionDrifted, Status: metav1.ConditionFalse, Reason: "NoDrift", Message: status.Message}
+	if status.Drifted && status.Remediated {
+		condition.Status, condition.Reason = metav1.ConditionFalse, "DriftRemediated"
+	} else if status.Drifted {
+		condition.Status, condition.Reason = metav1.ConditionTrue, "DriftDetected"
+	}
+	return condition
+}
+
+// setDrift stores the drift of the CR and reports whether it has changed, empty drift means the objects match
+func (r *ReconcileCommonService) setDrift(request reconcile.Request, drift string) bool {
+	r.driftMutex.Lock()
+	defer r.driftMutex.Unlock()
+	if r.drift == nil {
+		r.drift = make(map[string]string)
+	}
+	if r.drift[request.String()] == drift {
+		return false
+	}
+	if drift != "" {
+		r.drift[request.String()] = drift
+	} else {
+		delete(r.drift, request.String())
+	}
+	return true
+}
+
+func driftFingerprint(status types.DriftStatus) string {
+	if !status.Drifted {
+		return ""
+	}
+	return fmt.Sprintf("%s, remediated: %v", status.Message, status.Remediated)
+}
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	apiTypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type testDriftReconciler struct {
+	testConditionsReconciler
+	driftStatus types.DriftStatus
+}
+
+func (r *testDriftReconciler) UpdateDriftStatus(status types.DriftStatus) {
+	r.driftStatus = status
+}
+
+type testDesiredObjectsBuilder struct {
+	objects func() []client.Object
+}
+
+func (r *testDesiredObjectsBuilder) BuildDesiredObjects(ctx ExecutionContext) ([]client.Object, error) {
+	return r.objects(), nil
+}
+
+func TestDriftDetection(t *testing.T) {
+	request := reconcile.Request{NamespacedName: apiTypes.NamespacedName{Namespace: "namespace", Name: "cr"}}
+	desiredService := func() client.Object {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "db", Labels: map[string]string{"app": "db"}},
+			Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Name: "db", Port: 5432}}},
+		}
+	}
+	desiredConfig := func() client.Object {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "db-config"},
+			Data:       map[string]string{"mode": "cluster"},
+		}
+	}
+
+	// the live service has server-populated fields and a manually changed port name
+	liveService := desiredService().(*v1.Service)
+	liveService.Spec.ClusterIP = "10.0.0.1"
+	liveService.Spec.Ports[0].Name = "manual"
+	setServerDefaults(liveService)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "cr"}}
+	kubeClient := newServerClient(liveService, pod)
+
+	instance := &v1.Pod{}
+	assert.Nil(t, kubeClient.Get(context.Background(), request.NamespacedName, instance))
+	reconciler := &testDriftReconciler{testConditionsReconciler: testConditionsReconciler{instance: instance}}
+	r := &ReconcileCommonService{
+		Client:     kubeClient,
+		Scheme:     scheme.Scheme,
+		Reconciler: reconciler,
+		DriftDetection: &DriftDetection{
+			Builder: &testDesiredObjectsBuilder{objects: func() []client.Object {
+				return []client.Object{desiredService(), desiredConfig()}
+			}},
+		},
+	}
+	crHandler := DefaultCRStatusHandler{Reconciler: reconciler, KubeClient: kubeClient}
+	ctx := NewInitExecutionContext(map[string]interface{}{})
+	logger := GetLogger(true)
+
+	drifted, err := DetectDrift(context.Background(), kubeClient, scheme.Scheme, desiredService(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.DriftedObject{Kind: "Service", Name: "db", Reason: DriftReasonModified, Fields: []string{"spec.ports[0].name"}}, drifted)
+
+	drifted, err = DetectDrift(context.Background(), kubeClient, scheme.Scheme, desiredService(), []string{"spec.ports"})
+	assert.Nil(t, err)
+	assert.Nil(t, drifted)
+
+	// false is compared as the desired value, not as the missing one
+	disabledNodePorts := desiredService().(*v1.Service)
+	disabledNodePorts.Spec.AllocateLoadBalancerNodePorts = new(bool)
+	drifted, err = DetectDrift(context.Background(), kubeClient, scheme.Scheme, disabledNodePorts, []string{"spec.ports"})
+	assert.Nil(t, err)
+	if assert.NotNil(t, drifted) {
+		assert.Equal(t, []string{"spec.allocateLoadBalancerNodePorts"}, drifted.Fields)
+	}
+
+	t.Run("Drift is reported", func(t *testing.T) {
+		r.checkDrift(ctx, crHandler, request, logger)
+		assert.True(t, reconciler.driftStatus.Drifted)
+		assert.Len(t, reconciler.driftStatus.Objects, 2)
+		assert.Equal(t, DriftReasonMissing, reconciler.driftStatus.Objects[1].Reason)
+		assert.True(t, meta.IsStatusConditionTrue(reconciler.conditions, ConditionDrifted))
+	})
+
+	t.Run("Drift is remediated", func(t *testing.T) {
+		r.DriftDetection.Remediate = true
+		r.checkDrift(ctx, crHandler, request, logger)
+		assert.True(t, reconciler.driftStatus.Remediated)
+
+		service := &v1.Service{}
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(liveService), service))
+		assert.Equal(t, "db", service.Spec.Ports[0].Name)
+		assert.Equal(t, "10.0.0.1", service.Spec.ClusterIP)
+		assert.Equal(t, intstr.FromInt32(5432), service.Spec.Ports[0].TargetPort)
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(desiredConfig()), &v1.ConfigMap{}))
+
+		r.checkDrift(ctx, crHandler, request, logger)
+		assert.False(t, reconciler.driftStatus.Drifted)
+		assert.True(t, meta.IsStatusConditionFalse(reconciler.conditions, ConditionDrifted))
+	})
+
+	t.Run("Stored drift is compared after the operator restart", func(t *testing.T) {
+		restarted := &ReconcileCommonService{
+			Client:         kubeClient,
+			Scheme:         scheme.Scheme,
+			Reconciler:     reconciler,
+			DriftDetection: &DriftDetection{Builder: r.DriftDetection.Builder},
+		}
+		// the status is not committed if it matches the stored one
+		reconciler.driftStatus = types.DriftStatus{Message: "not committed"}
+		restarted.checkDrift(ctx, crHandler, request, logger)
+		assert.Equal(t, "not committed", reconciler.driftStatus.Message)
+
+		// the drift reported before the restart is resolved
+		meta.SetStatusCondition(&reconciler.conditions, metav1.Condition{Type: ConditionDrifted, Status: metav1.ConditionTrue, Reason: "DriftDetected"})
+		restarted.checkDrift(ctx, crHandler, request, logger)
+		assert.False(t, reconciler.driftStatus.Drifted)
+		assert.Empty(t, reconciler.driftStatus.Message)
+		assert.True(t, meta.IsStatusConditionFalse(reconciler.conditions, ConditionDrifted))
+	})
+}
+
+func TestDriftOfServerDefaults(t *testing.T) {
+	desiredService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "db"},
+		Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Name: "db", Port: 5432}}},
+	}
+	desiredSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "db-credentials"},
+		StringData: map[string]string{"password": "secret"},
+	}
+
+	t.Run("Defaults are read with the dry run", func(t *testing.T) {
+		kubeClient := newServerClient()
+		assert.Nil(t, kubeClient.Create(context.Background(), desiredService.DeepCopy()))
+		assert.Nil(t, kubeClient.Create(context.Background(), desiredSecret.DeepCopy()))
+
+		for _, desired := range []client.Object{desiredService, desiredSecret} {
+			drifted, err := DetectDrift(context.Background(), kubeClient, scheme.Scheme, desired, nil)
+			assert.Nil(t, err)
+			assert.Nil(t, drifted, desired.GetName())
+		}
+
+		// the write-only field is compared as the stored data
+		secret := &v1.Secret{}
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(desiredSecret), secret))
+		secret.Data["password"] = []byte("changed")
+		assert.Nil(t, kubeClient.Update(context.Background(), secret))
+		drifted, err := DetectDrift(context.Background(), kubeClient, scheme.Scheme, desiredSecret, nil)
+		assert.Nil(t, err)
+		if assert.NotNil(t, drifted) {
+			assert.Equal(t, []string{"data.password"}, drifted.Fields)
+			assert.Nil(t, RemediateDrift(context.Background(), kubeClient, scheme.Scheme, desiredSecret, drifted, nil))
+		}
+		assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(desiredSecret), secret))
+		assert.Equal(t, "secret", string(secret.Data["password"]))
+	})
+
+	t.Run("Zero numbers are not compared without the dry run", func(t *testing.T) {
+		liveService := desiredService.DeepCopy()
+		setServerDefaults(liveService)
+		liveSecret := desiredSecret.DeepCopy()
+		setServerDefaults(liveSecret)
+		// the fake client supports neither the apply nor the defaults
+		kubeClient := fake.NewClientBuilder().WithObjects(liveService, liveSecret).Build()
+
+		for _, desired := range []client.Object{desiredService, desiredSecret} {
+			drifted, err := DetectDrift(context.Background(), kubeClient, scheme.Scheme, desired, nil)
+			assert.Nil(t, err)
+			assert.Nil(t, drifted, desired.GetName())
+		}
+	})
+}
+
+// newServerClient returns the fake client which sets the defaults of the API server on create
+// and emulates the server-side apply with the strategic merge patch
+func newServerClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithObjects(objects...).
+		WithStatusSubresource(&v1.Pod{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				setServerDefaults(obj)
+				return c.Create(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch.Type() != apiTypes.ApplyPatchType {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+				return applyAsServer(ctx, c, obj.(*unstructured.Unstructured), patch, opts...)
+			},
+		}).
+		Build()
+}
+
+func applyAsServer(ctx context.Context, c client.WithWatch, obj *unstructured.Unstructured, patch client.Patch, opts ...client.PatchOption) error {
+	options := &client.PatchOptions{}
+	options.ApplyOptions(opts)
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	typed, err := c.Scheme().New(obj.GroupVersionKind())
+	if err != nil {
+		return err
+	}
+	live := typed.(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return err
+	}
+	liveData, err := json.Marshal(live)
+	if err != nil {
+		return err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(liveData, data, typed)
+	if err != nil {
+		return err
+	}
+	result := newObjectOf(live)
+	if err := json.Unmarshal(merged, result); err != nil {
+		return err
+	}
+	setServerDefaults(result)
+	if len(options.DryRun) == 0 {
+		if err := c.Update(ctx, result); err != nil {
+			return err
+		}
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(result)
+	if err != nil {
+		return err
+	}
+	obj.SetUnstructuredContent(content)
+	return nil
+}
+
+// setServerDefaults sets the fields of the objects as the API server does it
+func setServerDefaults(object client.Object) {
+	switch o := object.(type) {
+	case *v1.Service:
+		if o.Spec.Type == "" {
+			o.Spec.Type = v1.ServiceTypeClusterIP
+		}
+		for i := range o.Spec.Ports {
+			port := &o.Spec.Ports[i]
+			if port.Protocol == "" {
+				port.Protocol = v1.ProtocolTCP
+			}
+			if port.TargetPort == (intstr.IntOrString{}) {
+				port.TargetPort = intstr.FromInt32(port.Port)
+			}
+		}
+	case *v1.Secret:
+		if len(o.StringData) > 0 && o.Data == nil {
+			o.Data = map[string][]byte{}
+		}
+		for key, value := range o.StringData {
+			o.Data[key] = []byte(value)
+		}
+		o.StringData = nil
+	}
+}
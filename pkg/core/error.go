@@ -1,13 +1,24 @@
 package core
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ExecutionError struct {
 	Msg string
+	// Err is an optional cause of the error
+	Err error
 }
 
 func (r *ExecutionError) Error() string {
 	return r.Msg
 }
 
+func (r *ExecutionError) Unwrap() error {
+	return r.Err
+}
+
 type DRExecutionError struct {
 	Msg string
 }
@@ -27,3 +38,46 @@ func (r *NotFoundError) Error() string {
 func NewNotFoundError(msg string) *NotFoundError {
 	return &NotFoundError{Msg: msg}
 }
+
+// AggregatedError holds errors of several steps which have been executed independently
+type AggregatedError struct {
+	Errors []error
+}
+
+func (r *AggregatedError) Error() string {
+	messages := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (r *AggregatedError) Unwrap() []error {
+	return r.Errors
+}
+
+// NewAggregatedError returns nil if there are no errors in the list
+func NewAggregatedError(errs []error) error {
+	var result []error
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return &AggregatedError{Errors: result}
+}
+
+// PanicToError converts a value recovered from a panic into an error
+func PanicToError(recovered interface{}) error {
+	switch v := recovered.(type) {
+	case error:
+		return v
+	case string:
+		return &ExecutionError{Msg: v}
+	default:
+		return &ExecutionError{Msg: fmt.Sprintf("%v", v)}
+	}
+}
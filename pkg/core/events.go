@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the events emitted by the core
+const (
+	EventReasonReconcileStarted         = "ReconcileStarted"
+	EventReasonSpecChanged              = "SpecChanged"
+	EventReasonServiceDeploymentStarted = "ServiceDeploymentStarted"
+	EventReasonServiceDeployed          = "ServiceDeployed"
+	EventReasonServiceDeploymentFailed  = "ServiceDeploymentFailed"
+	EventReasonStepFailed               = "StepFailed"
+	EventReasonDRStatusChanged          = "DRStatusChanged"
+	EventReasonPasswordUpdated          = "PasswordUpdated"
+	EventReasonPasswordUpdateFailed     = "PasswordUpdateFailed"
+	EventReasonStandby                  = "Standby"
+	EventReasonStandbyFinished          = "StandbyFinished"
+	EventReasonCleanupStarted           = "CleanupStarted"
+	EventReasonCleanedUp                = "CleanedUp"
+	EventReasonCleanupFailed            = "CleanupFailed"
+	EventReasonPaused                   = "Paused"
+	EventReasonResumed                  = "Resumed"
+	EventReasonForceReconcile           = "ForceReconcile"
+	EventReasonDriftDetected            = "DriftDetected"
+	EventReasonDriftRemediated          = "DriftRemediated"
+	EventReasonDriftResolved            = "DriftResolved"
+)
+
+const DefaultEventDeduplicationInterval = 5 * time.Minute
+
+// EventEmitter records Kubernetes events and drops the same event of the same object
+// if it has already been emitted within the deduplication interval
+type EventEmitter struct {
+	Recorder record.EventRecorder
+	// Interval is the deduplication interval, DefaultEventDeduplicationInterval is used if not set
+	Interval time.Duration
+	emitted  map[string]time.Time
+	mutex    sync.Mutex
+}
+
+func NewEventEmitter(recorder record.EventRecorder) *EventEmitter {
+	return &EventEmitter{Recorder: recorder, emitted: make(map[string]time.Time)}
+}
+
+// Emit records the event, it's a no-op for nil emitter or recorder
+func (e *EventEmitter) Emit(object runtime.Object, eventType string, reason string, message string) {
+	if e == nil || e.Recorder == nil || object == nil {
+		return
+	}
+	if !e.allow(eventKey(object, eventType, reason, message)) {
+		return
+	}
+	e.Recorder.Event(object, eventType, reason, message)
+}
+
+func (e *EventEmitter) allow(key string) bool {
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultEventDeduplicationInterval
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.emitted == nil {
+		e.emitted = make(map[string]time.Time)
+	}
+	now := time.Now()
+	for emittedKey, emittedAt := range e.emitted {
+		if now.Sub(emittedAt) >= interval {
+			delete(e.emitted, emittedKey)
+		}
+	}
+	if _, ok := e.emitted[key]; ok {
+		return false
+	}
+	e.emitted[key] = now
+	return true
+}
+
+func eventKey(object runtime.Object, eventType string, reason string, message string) string {
+	objectKey := fmt.Sprintf("%T", object)
+	if accessor, err := meta.Accessor(object); err == nil {
+		objectKey = fmt.Sprintf("%s/%s/%s", accessor.GetNamespace(), accessor.GetName(), accessor.GetUID())
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", objectKey, eventType, reason, message)
+}
+
+// EmitEvent records the event on the CR of the execution context if the emitter is configured
+func EmitEvent(ctx ExecutionContext, eventType string, reason string, message string) {
+	emitter, ok := EventEmitterKey.Lookup(ctx)
+	if !ok {
+		return
+	}
+	spec, ok := SpecKey.Lookup(ctx)
+	if !ok {
+		return
+	}
+	emitter.Emit(spec, eventType, reason, message)
+}
+
+func EmitNormalEvent(ctx ExecutionContext, reason string, message string) {
+	EmitEvent(ctx, corev1.EventTypeNormal, reason, message)
+}
+
+func EmitWarningEvent(ctx ExecutionContext, reason string, message string) {
+	EmitEvent(ctx, corev1.EventTypeWarning, reason, message)
+}
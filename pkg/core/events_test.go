@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	emitter := NewEventEmitter(recorder)
+
+	service := &MicroServiceCompound{
+		ServiceName: "fake-service",
+		CalcDeployType: func(ctx ExecutionContext) (MicroServiceDeployType, error) {
+			return Update, nil
+		},
+	}
+	service.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		return errors.New("pvc is not bound")
+	}})
+	root := &DefaultCompound{}
+	root.AddStep(service)
+
+	execute := func() {
+		executor := DefaultExecutor()
+		executor.SetExecutable(root)
+		ctx := NewInitExecutionContext(map[string]interface{}{
+			SpecKey.Name():         &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns"}},
+			EventEmitterKey.Name(): emitter,
+		})
+		assert.NotNil(t, executor.Execute(ctx))
+	}
+	execute()
+	// the same events are deduplicated
+	execute()
+
+	close(recorder.Events)
+	var events []string
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	assert.Len(t, events, 3)
+	assert.Equal(t, "Normal ServiceDeploymentStarted Deployment of fake-service started, deploy type: Update", events[0])
+	assert.Equal(t, "Warning StepFailed Step DefaultCompound/MicroServiceCompound/testStep failed: pvc is not bound", events[1])
+	assert.Contains(t, events[2], "Warning ServiceDeploymentFailed Deployment of fake-service failed")
+}
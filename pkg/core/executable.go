@@ -17,3 +17,34 @@ func (r *DefaultExecutable) Validate(ctx ExecutionContext) error {
 func (r *DefaultExecutable) Condition(ctx ExecutionContext) (bool, error) {
 	return true, nil
 }
+
+// DependentExecutable is implemented by steps which declare execution context variables they consume and produce.
+// DAGCompound uses these declarations to order its steps.
+type DependentExecutable interface {
+	Executable
+	Requires() []string
+	Produces() []string
+}
+
+// Rollbackable is implemented by steps which are able to compensate their changes.
+// Compounds with enabled RollbackOnFailure call it for completed steps in reverse order if one of the next steps fails.
+type Rollbackable interface {
+	Rollback(ctx ExecutionContext) error
+}
+
+// StepWrapper is implemented by decorators of the steps, e.g. RetryStep.
+// Step names, dependencies and rollback are taken from the wrapped step.
+type StepWrapper interface {
+	Unwrap() Executable
+}
+
+// UnwrapStep returns the innermost step of the decorators chain
+func UnwrapStep(element Executable) Executable {
+	for {
+		wrapper, ok := element.(StepWrapper)
+		if !ok {
+			return element
+		}
+		element = wrapper.Unwrap()
+	}
+}
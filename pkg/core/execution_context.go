@@ -1,7 +1,7 @@
 package core
 
 import (
-	"fmt"
+	"sync"
 )
 
 type ExecutionContext interface {
@@ -9,9 +9,11 @@ type ExecutionContext interface {
 	Get(str string) interface{}
 }
 
+// DefaultExecutionContext is safe for concurrent use, so steps of a ParallelCompound may share it.
 type DefaultExecutionContext struct {
 	ExecutionContext
 	contextVars map[string]interface{}
+	mutex       sync.RWMutex
 }
 
 func NewDefaultExecutionContext() *DefaultExecutionContext {
@@ -23,17 +25,20 @@ func NewInitExecutionContext(initValues map[string]interface{}) *DefaultExecutio
 }
 
 func (r *DefaultExecutionContext) Set(str string, obj interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.contextVars[str] = obj
 }
 
 func (r *DefaultExecutionContext) Get(str string) interface{} {
-	if r.contextVars[str] == nil {
-		println(fmt.Sprintf("No variable %s in execution context", str)) //todo log?
-	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 	return r.contextVars[str]
 }
 
 func (r *DefaultExecutionContext) IsAnyNil(str ...string) (bool, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 	for _, element := range str {
 		res := r.contextVars[element]
 		if res == nil {
@@ -1,8 +1,12 @@
 package core
 
+import ()
+
 type Executor struct {
 	executable        *interface{}
 	executionStrategy func(executable *interface{}, ctx ExecutionContext) error
+	dryRun            bool
+	interceptors      []StepInterceptor
 }
 
 func (r *Executor) SetExecutable(executable Executable) {
@@ -11,22 +15,55 @@ func (r *Executor) SetExecutable(executable Executable) {
 }
 
 func (r *Executor) Execute(ctx ExecutionContext) error {
-	return r.executionStrategy(r.executable, ctx)
+	return r.executionStrategy(r.executable, withInterceptors(ctx, r.interceptors))
+}
+
+// Use registers interceptors which are applied to all steps of the executable tree
+func (r *Executor) Use(interceptors ...StepInterceptor) {
+	r.interceptors = append(r.interceptors, interceptors...)
+}
+
+// IsDryRun reports whether the executor only plans the execution
+func (r *Executor) IsDryRun() bool {
+	return r.dryRun
 }
 
+// DefaultExecutor validates and executes the root step. Results of the steps are collected
+// to the execution report which can be obtained with GetExecutionReportCollector.
 func DefaultExecutor() Executor {
 	strategy := func(executable *interface{}, ctx ExecutionContext) error {
+		root := (*executable).(Executable)
+		if GetExecutionReportCollector(ctx) == nil {
+			ExecutionReportKey.Set(ctx, NewExecutionReportCollector())
+		}
+		rootCtx, span := startStepSpan(withStepPath(ctx, GetStepName(root)), root)
+		finish := recordStep(rootCtx)
 		steps := []func() error{
-			func() error { return ((*executable).(Executable)).Validate(ctx) },
-			func() error { return ((*executable).(Executable)).Execute(ctx) },
+			func() error { return callStep(rootCtx, func() error { return root.Validate(rootCtx) }) },
+			func() error { return callStep(rootCtx, func() error { return root.Execute(rootCtx) }) },
 		}
 		for _, element := range steps {
 			err := element()
 			if err != nil {
+				finish(StepFailed, err)
+				endStepSpan(span, StepFailed, err)
 				return err
 			}
 		}
+		finish(StepExecuted, nil)
+		endStepSpan(span, StepExecuted, nil)
 		return nil
 	}
 	return Executor{executionStrategy: strategy}
 }
+
+// PlanExecutor runs Validate and Condition of every step in the tree without executing them.
+// The plan is stored to the execution context and can be obtained with GetExecutionPlan.
+func PlanExecutor() Executor {
+	strategy := func(executable *interface{}, ctx ExecutionContext) error {
+		plan, err := BuildExecutionPlan((*executable).(Executable), ctx)
+		ExecutionPlanKey.Set(ctx, plan)
+		return err
+	}
+	return Executor{executionStrategy: strategy, dryRun: true}
+}
@@ -10,10 +10,13 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"reflect"
 	"strings"
 	"time"
 
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	v14 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -27,7 +30,6 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type KubernetesHelper interface {
@@ -65,10 +67,66 @@ type DefaultKubernetesHelperImpl struct {
 	ForceKey bool
 	OwnerKey bool
 	Client   client.Client
+	// ctx is set on the copy of the helper returned by GetKubernetesHelper, background context is used if not set
+	ctx context.Context
 }
 
 var _ KubernetesHelper = &DefaultKubernetesHelperImpl{}
 
+var defaultKubernetesHelperType = reflect.TypeOf(DefaultKubernetesHelperImpl{})
+
+// GetKubernetesHelper returns the helper of the context bound to the Go context of the step,
+// so its calls and waits are cancelled on the operator shutdown and with the step timeout.
+// The helper is copied, so the types embedding DefaultKubernetesHelperImpl keep their methods.
+// Other helpers and the ones embedding it by pointer are returned as is.
+func GetKubernetesHelper(ctx ExecutionContext) KubernetesHelper {
+	helper := KubernetesHelperKey.MustGet(ctx)
+	value := reflect.ValueOf(helper)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return helper
+	}
+	copied := reflect.New(value.Elem().Type())
+	copied.Elem().Set(value.Elem())
+	impl := findDefaultKubernetesHelper(copied.Elem())
+	if impl == nil {
+		return helper
+	}
+	impl.ctx = GetContext(ctx)
+	return copied.Interface().(KubernetesHelper)
+}
+
+// findDefaultKubernetesHelper returns DefaultKubernetesHelperImpl embedded by value into the struct
+func findDefaultKubernetesHelper(value reflect.Value) *DefaultKubernetesHelperImpl {
+	if value.Type() == defaultKubernetesHelperType {
+		return value.Addr().Interface().(*DefaultKubernetesHelperImpl)
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.IsExported() {
+			if impl := findDefaultKubernetesHelper(value.Field(i)); impl != nil {
+				return impl
+			}
+		}
+	}
+	return nil
+}
+
+func (r *DefaultKubernetesHelperImpl) goContext() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return context.Background()
+}
+
+// pollImmediate checks the condition every second until it's satisfied, the timeout is exceeded or the context is done.
+// The wait is traced as a span with the given name.
+func pollImmediate(ctx context.Context, name string, timeoutSeconds int, condition wait.ConditionFunc) error {
+	ctx, span := tracing.StartSpan(ctx, name, attribute.Int("qubership.wait.timeout_seconds", timeoutSeconds))
+	err := wait.PollUntilContextTimeout(ctx, time.Second, time.Second*time.Duration(timeoutSeconds), true, condition.WithContext())
+	tracing.EndSpan(span, err)
+	return err
+}
+
 func (r *DefaultKubernetesHelperImpl) execCommand(name string, arg []string, stdInData string) ([]byte, error) {
 	command := exec.Command(name, arg...)
 	var stderr bytes.Buffer
@@ -97,7 +155,7 @@ func (r *DefaultKubernetesHelperImpl) GetOwnerKey() bool {
 
 func (r *DefaultKubernetesHelperImpl) GetConfigMap(name, namespace string) (*v1.ConfigMap, error) {
 	cm := &v1.ConfigMap{}
-	err := r.Client.Get(context.TODO(),
+	err := r.Client.Get(r.goContext(),
 		types.NamespacedName{Name: name, Namespace: namespace}, cm)
 
 	if err != nil {
@@ -114,7 +172,7 @@ func (r *DefaultKubernetesHelperImpl) ListPods(namespace string, labelSelectors
 		client.InNamespace(namespace),
 		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(labelSelectors)},
 	}
-	err := r.Client.List(context.Background(), podList, listOps...)
+	err := r.Client.List(r.goContext(), podList, listOps...)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +185,7 @@ func (r *DefaultKubernetesHelperImpl) checkPodsCountByLabel(labelSelectors map[s
 		client.InNamespace(namespace),
 		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(labelSelectors)},
 	}
-	if err := r.Client.List(context.Background(), podList, listOps...); err != nil {
+	if err := r.Client.List(r.goContext(), podList, listOps...); err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil
 		}
@@ -138,7 +196,7 @@ func (r *DefaultKubernetesHelperImpl) checkPodsCountByLabel(labelSelectors map[s
 }
 
 func (r *DefaultKubernetesHelperImpl) WaitForPodsCountByLabel(labelSelectors map[string]string, namespace string, numberOfPods int, waitSeconds int) error {
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(waitSeconds), func() (done bool, err error) {
+	return pollImmediate(r.goContext(), "WaitForPodsCountByLabel", waitSeconds, func() (done bool, err error) {
 		return r.checkPodsCountByLabel(labelSelectors, namespace, numberOfPods)
 	})
 }
@@ -150,7 +208,7 @@ func (r *DefaultKubernetesHelperImpl) checkPodsByLabel(labelSelectors map[string
 		client.InNamespace(namespace),
 		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(labelSelectors)},
 	}
-	if err := r.Client.List(context.Background(), podList, listOps...); err != nil {
+	if err := r.Client.List(r.goContext(), podList, listOps...); err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil
 		}
@@ -178,7 +236,7 @@ func (r *DefaultKubernetesHelperImpl) checkPodsByLabel(labelSelectors map[string
 }
 
 func (r *DefaultKubernetesHelperImpl) WaitForPodsCompleted(labelSelectors map[string]string, namespace string, numberOfPods int, waitSeconds int) error {
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(waitSeconds), func() (done bool, err error) {
+	return pollImmediate(r.goContext(), "WaitForPodsCompleted", waitSeconds, func() (done bool, err error) {
 		return r.checkPodsByLabel(labelSelectors, namespace, numberOfPods, v1.PodSucceeded, func(status v1.ContainerStatus) (bool, error) {
 			terminated := status.State.Terminated
 			if terminated != nil {
@@ -199,7 +257,7 @@ func (r *DefaultKubernetesHelperImpl) WaitForPodsCompleted(labelSelectors map[st
 }
 
 func (r *DefaultKubernetesHelperImpl) WaitForPodsReady(labelSelectors map[string]string, namespace string, numberOfPods int, waitSeconds int) error {
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(waitSeconds), func() (done bool, err error) {
+	return pollImmediate(r.goContext(), "WaitForPodsReady", waitSeconds, func() (done bool, err error) {
 		return r.checkPodsByLabel(labelSelectors, namespace, numberOfPods, v1.PodRunning, func(status v1.ContainerStatus) (bool, error) {
 			return status.Ready, nil
 		})
@@ -207,9 +265,9 @@ func (r *DefaultKubernetesHelperImpl) WaitForPodsReady(labelSelectors map[string
 }
 
 func (r *DefaultKubernetesHelperImpl) WaitForDeploymentReady(deployName string, namespace string, waitSeconds int) error {
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(waitSeconds), func() (done bool, err error) {
+	return pollImmediate(r.goContext(), "WaitForDeploymentReady", waitSeconds, func() (done bool, err error) {
 		d := &v14.Deployment{}
-		if err := r.Client.Get(context.Background(), types.NamespacedName{Name: deployName, Namespace: namespace}, d); err != nil {
+		if err := r.Client.Get(r.goContext(), types.NamespacedName{Name: deployName, Namespace: namespace}, d); err != nil {
 			return false, err
 		}
 		if d.Status.ReadyReplicas != d.Status.Replicas {
@@ -221,9 +279,9 @@ func (r *DefaultKubernetesHelperImpl) WaitForDeploymentReady(deployName string,
 }
 
 func (r *DefaultKubernetesHelperImpl) WaitForTestsReady(deployName string, namespace string, waitSeconds int) error {
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(waitSeconds), func() (done bool, err error) {
+	return pollImmediate(r.goContext(), "WaitForTestsReady", waitSeconds, func() (done bool, err error) {
 		dc := &v14.Deployment{}
-		err = r.Client.Get(context.Background(), types.NamespacedName{Name: deployName, Namespace: namespace}, dc)
+		err = r.Client.Get(r.goContext(), types.NamespacedName{Name: deployName, Namespace: namespace}, dc)
 
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -249,9 +307,9 @@ func (r *DefaultKubernetesHelperImpl) WaitForTestsReady(deployName string, names
 }
 
 func (r *DefaultKubernetesHelperImpl) WaitForPVCBound(pvcName string, namespace string, waitSeconds int) error {
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(waitSeconds), func() (done bool, err error) {
+	return pollImmediate(r.goContext(), "WaitForPVCBound", waitSeconds, func() (done bool, err error) {
 		foundPvc := &v1.PersistentVolumeClaim{}
-		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: namespace}, foundPvc); err != nil {
+		if err := r.Client.Get(r.goContext(), types.NamespacedName{Name: pvcName, Namespace: namespace}, foundPvc); err != nil {
 			if errors.IsNotFound(err) {
 				return false, nil
 			}
@@ -332,7 +390,7 @@ func (r *DefaultKubernetesHelperImpl) GetPodLogs(kubeConfig *rest.Config, podNam
 		},
 	)
 
-	resp, err := execRequest.DoRaw(context.Background())
+	resp, err := execRequest.DoRaw(r.goContext())
 
 	return string(resp), err
 }
@@ -352,14 +410,20 @@ func (r *DefaultKubernetesHelperImpl) ListRuntimeObjectsByLabels(list client.Obj
 		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(labelSelectors)},
 	}
 
-	err := r.Client.List(context.Background(), list, listOps...)
+	err := r.Client.List(r.goContext(), list, listOps...)
 
 	return err
 }
 
 func ListRuntimeObjectsByName(obj client.Object,
 	kubeClient client.Client, namespace string, name string) error {
-	err := kubeClient.Get(context.Background(), types.NamespacedName{
+	return ListRuntimeObjectsByNameContext(context.Background(), obj, kubeClient, namespace, name)
+}
+
+// ListRuntimeObjectsByNameContext reads the object by name, the request is cancelled with the context
+func ListRuntimeObjectsByNameContext(ctx context.Context, obj client.Object,
+	kubeClient client.Client, namespace string, name string) error {
+	err := kubeClient.Get(ctx, types.NamespacedName{
 		Namespace: namespace,
 		Name:      name,
 	}, obj)
@@ -369,7 +433,7 @@ func ListRuntimeObjectsByName(obj client.Object,
 
 func (r *DefaultKubernetesHelperImpl) DeleteDeploymentAndPods(dcName string, namespace string, waitSeconds int) error {
 	oldDeployment := v14.Deployment{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{
+	err := r.Client.Get(r.goContext(), types.NamespacedName{
 		Name: dcName, Namespace: namespace,
 	}, &oldDeployment)
 	if err != nil {
@@ -377,7 +441,7 @@ func (r *DefaultKubernetesHelperImpl) DeleteDeploymentAndPods(dcName string, nam
 			return &ExecutionError{Msg: "Error happened on checking " + dcName + " deployment. Error: " + err.Error()}
 		}
 	} else {
-		err = DeleteRuntimeObjectWithCheck(r.Client, &oldDeployment, waitSeconds)
+		err = DeleteRuntimeObjectWithCheckContext(r.goContext(), r.Client, &oldDeployment, waitSeconds)
 		if err != nil {
 			return &ExecutionError{Msg: "Error happened on existed deployment deletion. Error: " + err.Error()}
 		}
@@ -398,7 +462,7 @@ func (r *DefaultKubernetesHelperImpl) DeleteDeploymentAndPods(dcName string, nam
 
 func (r *DefaultKubernetesHelperImpl) DeleteStatefulsetAndPods(ssName string, namespace string, waitSeconds int) error {
 	oldStatefulset := v14.StatefulSet{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{
+	err := r.Client.Get(r.goContext(), types.NamespacedName{
 		Name: ssName, Namespace: namespace,
 	}, &oldStatefulset)
 	if err != nil {
@@ -406,7 +470,7 @@ func (r *DefaultKubernetesHelperImpl) DeleteStatefulsetAndPods(ssName string, na
 			return &ExecutionError{Msg: "Error happened on checking " + ssName + " statefulset. Error: " + err.Error()}
 		}
 	} else {
-		err = DeleteRuntimeObjectWithCheck(r.Client, &oldStatefulset, waitSeconds)
+		err = DeleteRuntimeObjectWithCheckContext(r.goContext(), r.Client, &oldStatefulset, waitSeconds)
 		if err != nil {
 			return &ExecutionError{Msg: "Error happened on existed statefulset deletion. Error: " + err.Error()}
 		}
@@ -428,7 +492,7 @@ func (r *DefaultKubernetesHelperImpl) DeleteStatefulsetAndPods(ssName string, na
 // TODO duplicate code as ^
 func (r *DefaultKubernetesHelperImpl) DeleteRCAndPods(ssName string, namespace string, waitSeconds int) error {
 	oldRc := v1.ReplicationController{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{
+	err := r.Client.Get(r.goContext(), types.NamespacedName{
 		Name: ssName, Namespace: namespace,
 	}, &oldRc)
 	if err != nil {
@@ -436,7 +500,7 @@ func (r *DefaultKubernetesHelperImpl) DeleteRCAndPods(ssName string, namespace s
 			return &ExecutionError{Msg: "Error happened on checking " + ssName + " replication controller. Error: " + err.Error()}
 		}
 	} else {
-		err = DeleteRuntimeObjectWithCheck(r.Client, &oldRc, waitSeconds)
+		err = DeleteRuntimeObjectWithCheckContext(r.goContext(), r.Client, &oldRc, waitSeconds)
 		if err != nil {
 			return &ExecutionError{Msg: "Error happened on existed replication controller deletion. Error: " + err.Error()}
 		}
@@ -448,7 +512,7 @@ func (r *DefaultKubernetesHelperImpl) DeleteRCAndPods(ssName string, namespace s
 		}
 
 		for _, pod := range podList.Items {
-			err = DeleteRuntimeObject(r.Client, &pod)
+			err = DeleteRuntimeObjectContext(r.goContext(), r.Client, &pod)
 			if err != nil {
 				return &ExecutionError{Msg: "Error happened while deleting mongos pods. Error: " + err.Error()}
 			}
@@ -471,8 +535,8 @@ func (r *DefaultKubernetesHelperImpl) DeleteRCAndPods(ssName string, namespace s
 // TODO remove service name
 func (r *DefaultKubernetesHelperImpl) GetDeploymentTypeByPVC(ctx ExecutionContext, serviceName string,
 	pvcSelector map[string]string) (MicroServiceDeployType, error) {
-	request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	request := RequestKey.MustGet(ctx)
+	log := LoggerKey.MustGet(ctx)
 
 	pvcList := &v1.PersistentVolumeClaimList{}
 	err := r.ListRuntimeObjectsByLabels(pvcList, request.Namespace, pvcSelector)
@@ -523,7 +587,7 @@ func (r *DefaultKubernetesHelperImpl) UpdateDeploymentByLabels(labels map[string
 
 	updateFunc(&dl.Items[0])
 
-	return r.Client.Update(context.TODO(), &dl.Items[0], &client.UpdateOptions{})
+	return r.Client.Update(r.goContext(), &dl.Items[0], &client.UpdateOptions{})
 }
 
 func (r *DefaultKubernetesHelperImpl) ScaleStatefulset(obj *v14.StatefulSet, replicas, timeout int) error {
@@ -539,7 +603,7 @@ func (r *DefaultKubernetesHelperImpl) ScaleReplicationController(obj *v1.Replica
 }
 
 func (r *DefaultKubernetesHelperImpl) scale(obj client.Object, replicas int, timeout int, labels map[string]string, namespace string) error {
-	err := r.Client.Patch(context.TODO(), obj, client.Merge, &client.PatchOptions{})
+	err := r.Client.Patch(r.goContext(), obj, client.Merge, &client.PatchOptions{})
 	if err != nil {
 		return err
 	}
@@ -557,11 +621,13 @@ func (r *DefaultKubernetesHelperImpl) scale(obj client.Object, replicas int, tim
 
 func (r *DefaultKubernetesHelperImpl) RestartPod(pod *v1.Pod, namespace string, waitSeconds int) error {
 	podDeleteTimeout := 5
-	err := DeleteRuntimeObject(r.Client, pod)
+	err := DeleteRuntimeObjectContext(r.goContext(), r.Client, pod)
 	if err != nil {
 		return fmt.Errorf("error while removal pod. Error: %v", err)
 	}
-	time.Sleep(time.Duration(podDeleteTimeout) * time.Second)
+	if err = sleepWithContext(r.goContext(), time.Duration(podDeleteTimeout)*time.Second); err != nil {
+		return err
+	}
 
 	err = r.WaitForPodsReady(
 		pod.ObjectMeta.Labels,
@@ -598,19 +664,14 @@ func ReadSecret(kubeClient client.Client, name string, namespace string) (*v1.Se
 }
 
 func CompareSpecToCM(ctx ExecutionContext, cfgTemplate *v1.ConfigMap, spec interface{}, serviceName string) bool {
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	log := LoggerKey.MustGet(ctx)
 
 	storedResVersion := cfgTemplate.Data[serviceName]
 
-	jsonBytes, jsonErr := json.Marshal(spec)
+	newResVersion, jsonErr := GetSpecHash(spec)
 	if jsonErr != nil {
 		log.Info(fmt.Sprintf("Failed to marshal spec to JSON, error: %v", jsonErr))
 	}
-
-	specHash := sha256.Sum256(jsonBytes)
-
-	// Prevent recycling
-	newResVersion := hex.EncodeToString(specHash[0:])
 	log.Info(fmt.Sprintf("Current %s version is: %s "+
 		"Stored %s Version is: %s", serviceName, newResVersion, serviceName, storedResVersion))
 
@@ -630,18 +691,27 @@ func CompareSpecToCM(ctx ExecutionContext, cfgTemplate *v1.ConfigMap, spec inter
 	}
 }
 
+// GetSpecHash returns sha256 of the spec JSON representation
+func GetSpecHash(spec interface{}) (string, error) {
+	jsonBytes, err := json.Marshal(spec)
+	specHash := sha256.Sum256(jsonBytes)
+
+	// Prevent recycling
+	return hex.EncodeToString(specHash[0:]), err
+}
+
 func GetSpecConfigMap(ctx ExecutionContext) *v1.ConfigMap {
-	request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	client := ctx.Get(constants.ContextClient).(client.Client)
-	contextHashConfigMap := ctx.Get(constants.ContextHashConfigMap).(string)
+	request := RequestKey.MustGet(ctx)
+	log := LoggerKey.MustGet(ctx)
+	client := ClientKey.MustGet(ctx)
+	contextHashConfigMap := HashConfigMapKey.MustGet(ctx)
 	cm := &v1.ConfigMap{
 		ObjectMeta: v12.ObjectMeta{
 			Namespace: request.Namespace,
 			Name:      contextHashConfigMap,
 		},
 	}
-	err := client.Get(context.TODO(), types.NamespacedName{
+	err := client.Get(GetContext(ctx), types.NamespacedName{
 		Name: cm.Name, Namespace: request.Namespace,
 	}, cm)
 
@@ -654,9 +724,9 @@ func GetSpecConfigMap(ctx ExecutionContext) *v1.ConfigMap {
 }
 
 func DeleteSpecConfigMap(ctx ExecutionContext) error {
-	request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-	client := ctx.Get(constants.ContextClient).(client.Client)
-	contextHashConfigMap := ctx.Get(constants.ContextHashConfigMap).(string)
+	request := RequestKey.MustGet(ctx)
+	client := ClientKey.MustGet(ctx)
+	contextHashConfigMap := HashConfigMapKey.MustGet(ctx)
 	cm := &v1.ConfigMap{
 		ObjectMeta: v12.ObjectMeta{
 			Namespace: request.Namespace,
@@ -664,12 +734,12 @@ func DeleteSpecConfigMap(ctx ExecutionContext) error {
 		},
 	}
 
-	return DeleteRuntimeObjectWithCheck(client, cm, 60)
+	return DeleteRuntimeObjectWithCheckContext(GetContext(ctx), client, cm, 60)
 }
 
 func UpdateSpecConfigMap(ctx ExecutionContext, cm *v1.ConfigMap) error {
-	client := ctx.Get(constants.ContextClient).(client.Client)
-	scheme := ctx.Get(constants.ContextSchema).(*runtime.Scheme)
+	client := ClientKey.MustGet(ctx)
+	scheme := SchemaKey.MustGet(ctx)
 
 	err := CreateOrUpdateRuntimeObject(
 		client,
@@ -683,17 +753,23 @@ func UpdateSpecConfigMap(ctx ExecutionContext, cm *v1.ConfigMap) error {
 }
 
 func HasSpecChanged(ctx ExecutionContext, checkChanges func(cfgTemplate *v1.ConfigMap) bool) (bool, error) {
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	kubeClient := ctx.Get(constants.ContextClient).(client.Client)
-	scheme := ctx.Get(constants.ContextSchema).(*runtime.Scheme)
-	spec := ctx.Get(constants.ContextSpec).(client.Object)
+	log := LoggerKey.MustGet(ctx)
+	kubeClient := ClientKey.MustGet(ctx)
+	scheme := SchemaKey.MustGet(ctx)
+	spec := SpecKey.MustGet(ctx)
 	resultCheck := false
 
 	cm := GetSpecConfigMap(ctx)
 
 	resultCheck = checkChanges(cm)
 
-	err := CreateOrUpdateRuntimeObjectAndWait(
+	if IsDryRun(ctx) {
+		log.Debug("Dry run: last reconcilation CR version is not stored")
+		return resultCheck, nil
+	}
+
+	err := CreateOrUpdateRuntimeObjectAndWaitContext(
+		GetContext(ctx),
 		kubeClient,
 		scheme,
 		spec,
@@ -708,13 +784,24 @@ func HasSpecChanged(ctx ExecutionContext, checkChanges func(cfgTemplate *v1.Conf
 }
 
 func CheckSpecChange(ctx ExecutionContext, spec interface{}, serviceName string) (bool, error) {
-	return HasSpecChanged(ctx, func(cfgTemplate *v1.ConfigMap) bool {
+	changed, err := HasSpecChanged(ctx, func(cfgTemplate *v1.ConfigMap) bool {
 		return CompareSpecToCM(ctx, cfgTemplate, spec, serviceName)
 	})
+	if changed {
+		request := RequestKey.Get(ctx)
+		metrics.SpecChanges.WithLabelValues(request.Namespace, request.Name, serviceName).Inc()
+		EmitNormalEvent(ctx, EventReasonSpecChanged, fmt.Sprintf("Changes of %s are detected", serviceName))
+	}
+	return changed, err
 }
 
 func DeleteRuntimeObjectWithCheck(cl client.Client, object client.Object, checkTimeout int) error {
-	err := DeleteRuntimeObject(cl, object)
+	return DeleteRuntimeObjectWithCheckContext(context.Background(), cl, object, checkTimeout)
+}
+
+// DeleteRuntimeObjectWithCheckContext deletes the object and waits for its removal until the context is done
+func DeleteRuntimeObjectWithCheckContext(ctx context.Context, cl client.Client, object client.Object, checkTimeout int) error {
+	err := cl.Delete(ctx, object)
 
 	if errors.IsNotFound(err) {
 		return nil
@@ -722,8 +809,8 @@ func DeleteRuntimeObjectWithCheck(cl client.Client, object client.Object, checkT
 
 	zeroObject := Zero(object)
 	emptyObject := (zeroObject).(client.Object)
-	return wait.PollImmediate(time.Second, time.Second*time.Duration(checkTimeout), func() (done bool, err error) {
-		err = cl.Get(context.TODO(), types.NamespacedName{
+	return pollImmediate(ctx, "DeleteRuntimeObjectWithCheck", checkTimeout, func() (done bool, err error) {
+		err = cl.Get(ctx, types.NamespacedName{
 			Name: object.GetName(), Namespace: object.GetNamespace(),
 		}, emptyObject)
 
@@ -741,7 +828,12 @@ func DeleteRuntimeObjectWithCheck(cl client.Client, object client.Object, checkT
 
 // TODO looks like it should be in helper
 func DeleteRuntimeObject(client client.Client, object client.Object) error {
-	err := client.Delete(context.TODO(), object)
+	return DeleteRuntimeObjectContext(context.Background(), client, object)
+}
+
+// DeleteRuntimeObjectContext deletes the object if it exists, the request is cancelled with the context
+func DeleteRuntimeObjectContext(ctx context.Context, client client.Client, object client.Object) error {
+	err := client.Delete(ctx, object)
 	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
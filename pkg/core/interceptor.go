@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+)
+
+// StepInterceptor is called around execution of every step which condition is satisfied.
+// It has to call next to execute the step, the step is reported as skipped if next is not called.
+type StepInterceptor func(step Executable, ctx ExecutionContext, next func(ctx ExecutionContext) error) error
+
+var StepInterceptorsKey = NewKey[[]StepInterceptor](constants.ContextStepInterceptors)
+
+// DefaultStepInterceptors are used by ReconcileCommonService if no interceptors are configured
+func DefaultStepInterceptors() []StepInterceptor {
+	return []StepInterceptor{LoggingInterceptor}
+}
+
+// LoggingInterceptor logs start and finish of the step
+func LoggingInterceptor(step Executable, ctx ExecutionContext, next func(ctx ExecutionContext) error) error {
+	logger, ok := LoggerKey.Lookup(ctx)
+	if !ok {
+		return next(ctx)
+	}
+	stepName := GetStepName(step)
+	logger.Info(fmt.Sprintf("Step %s started", stepName))
+	defer logger.Info(fmt.Sprintf("Step %s finished", stepName))
+	return next(ctx)
+}
+
+// withInterceptors appends the interceptors to the chain of the context, so they are applied to all nested steps
+func withInterceptors(ctx ExecutionContext, interceptors []StepInterceptor) ExecutionContext {
+	if len(interceptors) == 0 {
+		return ctx
+	}
+	parent := StepInterceptorsKey.Get(ctx)
+	chain := make([]StepInterceptor, 0, len(parent)+len(interceptors))
+	chain = append(chain, parent...)
+	chain = append(chain, interceptors...)
+	return withValue(ctx, StepInterceptorsKey.Name(), chain)
+}
+
+// interceptStep executes the step through the interceptors chain of the context in the registration order.
+// It reports whether the step has been actually executed.
+func interceptStep(ctx ExecutionContext, step Executable) (bool, error) {
+	chain := StepInterceptorsKey.Get(ctx)
+	executed := false
+	var call func(index int, ctx ExecutionContext) error
+	call = func(index int, ctx ExecutionContext) error {
+		if index == len(chain) {
+			executed = true
+			return step.Execute(ctx)
+		}
+		return chain[index](step, ctx, func(ctx ExecutionContext) error {
+			return call(index+1, ctx)
+		})
+	}
+	err := call(0, ctx)
+	return executed, err
+}
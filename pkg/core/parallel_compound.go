@@ -0,0 +1,61 @@
+package core
+
+import (
+	"sync"
+)
+
+// ParallelCompound runs its steps concurrently.
+// Steps must not depend on each other, all of them share the same ExecutionContext.
+// With RollbackOnFailure the steps completed before the failure are compensated in reverse order of their completion.
+type ParallelCompound struct {
+	DefaultCompound
+	// MaxWorkers limits the number of steps running at the same time. Zero means no limit
+	MaxWorkers int
+}
+
+func (r *ParallelCompound) Execute(ctx ExecutionContext) error {
+	ctx = withInterceptors(ctx, r.interceptors)
+	segments := stepPathSegments(r.executableSteps)
+	run := func(stepFunc func(stepCtx ExecutionContext, step Executable) error) error {
+		return runConcurrently(r.executableSteps, r.MaxWorkers, func(index int, element Executable) error {
+			return stepFunc(withStepPath(ctx, segments[index]), element)
+		})
+	}
+	if r.RollbackOnFailure || isRollbackTracked(ctx) {
+		return r.executeWithRollback(ctx, run)
+	}
+	return run(executeStep)
+}
+
+// runConcurrently calls stepFunc for every step using at most maxWorkers goroutines.
+// Panics raised inside of goroutines are converted to errors, all errors are aggregated.
+func runConcurrently(steps []Executable, maxWorkers int, stepFunc func(index int, step Executable) error) error {
+	if maxWorkers <= 0 || maxWorkers > len(steps) {
+		maxWorkers = len(steps)
+	}
+
+	errs := make([]error, len(steps))
+	semaphore := make(chan struct{}, MaxInt(maxWorkers, 1))
+	var wg sync.WaitGroup
+	for i, element := range steps {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, element Executable) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			errs[i] = callRecovered(func() error { return stepFunc(i, element) })
+		}(i, element)
+	}
+	wg.Wait()
+
+	return NewAggregatedError(errs)
+}
+
+func callRecovered(stepFunc func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = PanicToError(recovered)
+		}
+	}()
+	return stepFunc()
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"go.uber.org/zap"
+)
+
+type PlanAction string
+
+const (
+	PlanRun  PlanAction = "Run"
+	PlanSkip PlanAction = "Skip"
+	PlanFail PlanAction = "Fail"
+)
+
+type PlanEntry struct {
+	Path       string                 `json:"path"`
+	Action     PlanAction             `json:"action"`
+	DeployType MicroServiceDeployType `json:"deployType,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+}
+
+// ExecutionPlan describes which steps would be executed and which would be skipped
+type ExecutionPlan struct {
+	Entries         []PlanEntry `json:"entries"`
+	ValidationError string      `json:"validationError,omitempty"`
+}
+
+// Plannable is implemented by compounds which are able to describe their nested steps without executing them
+type Plannable interface {
+	Plan(ctx ExecutionContext, plan *ExecutionPlan) error
+}
+
+func (p *ExecutionPlan) HasFailures() bool {
+	if p.ValidationError != "" {
+		return true
+	}
+	for _, entry := range p.Entries {
+		if entry.Action == PlanFail {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ExecutionPlan) String() string {
+	var builder strings.Builder
+	if p.ValidationError != "" {
+		builder.WriteString(fmt.Sprintf("Validation failed: %s\n", p.ValidationError))
+	}
+	for _, entry := range p.Entries {
+		builder.WriteString(fmt.Sprintf("%-4s %s", entry.Action, entry.Path))
+		if entry.DeployType != Empty {
+			builder.WriteString(fmt.Sprintf(" [%s]", entry.DeployType))
+		}
+		if entry.Reason != "" {
+			builder.WriteString(": " + entry.Reason)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (p *ExecutionPlan) Log(logger *zap.Logger) {
+	logger.Info(fmt.Sprintf("Execution plan:\n%s", p.String()))
+}
+
+// BuildExecutionPlan validates the executable and evaluates conditions of all nested steps.
+// Neither Execute is called nor the spec hash config map is updated.
+func BuildExecutionPlan(root Executable, ctx ExecutionContext) (*ExecutionPlan, error) {
+	planCtx := withStepPath(withValue(ctx, constants.ContextDryRun, true), GetStepName(root))
+	plan := &ExecutionPlan{}
+
+	if err := callRecovered(func() error { return root.Validate(planCtx) }); err != nil {
+		plan.ValidationError = err.Error()
+		return plan, err
+	}
+
+	planStep(planCtx, root, plan)
+	return plan, nil
+}
+
+// GetExecutionPlan returns the plan built by PlanExecutor
+func GetExecutionPlan(ctx ExecutionContext) *ExecutionPlan {
+	return ExecutionPlanKey.Get(ctx)
+}
+
+// IsDryRun reports whether steps are only planned and must not change the cluster
+func IsDryRun(ctx ExecutionContext) bool {
+	return DryRunKey.Get(ctx)
+}
+
+func (r *DefaultCompound) Plan(ctx ExecutionContext, plan *ExecutionPlan) error {
+	return r.iterateOverSteps(ctx,
+		func(stepCtx ExecutionContext, element Executable) error {
+			planStep(stepCtx, element, plan)
+			return nil
+		})
+}
+
+func (r *DAGCompound) Plan(ctx ExecutionContext, plan *ExecutionPlan) error {
+	graph, err := r.buildGraph(ctx)
+	if err != nil {
+		return err
+	}
+	segments := stepPathSegments(r.executableSteps)
+	for _, index := range graph.order {
+		planStep(withStepPath(ctx, segments[index]), r.executableSteps[index], plan)
+	}
+	return nil
+}
+
+func (r *MicroServiceCompound) Plan(parentCtx ExecutionContext, plan *ExecutionPlan) error {
+	ctx := r.newScope(parentCtx)
+
+	deployTypeForService, err := r.CalcDeployType(ctx)
+	if err != nil {
+		return err
+	}
+	SetCurrentDeployType(ctx, deployTypeForService)
+
+	return r.DefaultCompound.Plan(ctx, plan)
+}
+
+func planStep(ctx ExecutionContext, element Executable, plan *ExecutionPlan) {
+	entry := PlanEntry{
+		Path:       GetStepPath(ctx),
+		DeployType: GetCurrentDeployType(ctx),
+	}
+
+	if !runsInDeployType(ctx, element) {
+		entry.Action = PlanSkip
+		entry.Reason = fmt.Sprintf("Step doesn't run in %s deploy type", entry.DeployType)
+		plan.Entries = append(plan.Entries, entry)
+		return
+	}
+
+	var run bool
+	err := callRecovered(func() (conditionErr error) {
+		run, conditionErr = element.Condition(ctx)
+		return
+	})
+	switch {
+	case err != nil:
+		entry.Action = PlanFail
+		entry.Reason = "Condition evaluation failed: " + err.Error()
+	case !run:
+		entry.Action = PlanSkip
+		entry.Reason = "Condition is not satisfied"
+	default:
+		entry.Action = PlanRun
+	}
+	plan.Entries = append(plan.Entries, entry)
+
+	if plannable, ok := UnwrapStep(element).(Plannable); ok && entry.Action == PlanRun {
+		index := len(plan.Entries) - 1
+		if planErr := callRecovered(func() error { return plannable.Plan(ctx, plan) }); planErr != nil {
+			plan.Entries[index].Action = PlanFail
+			plan.Entries[index].Reason = "Nested steps planning failed: " + planErr.Error()
+		}
+	}
+}
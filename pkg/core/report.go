@@ -0,0 +1,82 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	StepExecuted = "Executed"
+	StepSkipped  = "Skipped"
+	StepFailed   = "Failed"
+)
+
+// ExecutionReportCollector gathers results of the executed steps. It is safe for concurrent use.
+type ExecutionReportCollector struct {
+	steps []types.StepExecutionReport
+	mutex sync.Mutex
+}
+
+func NewExecutionReportCollector() *ExecutionReportCollector {
+	return &ExecutionReportCollector{}
+}
+
+// begin registers the started step and returns the function which completes its record
+func (r *ExecutionReportCollector) begin(path string) func(result string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	index := len(r.steps)
+	r.steps = append(r.steps, types.StepExecutionReport{
+		Path:      path,
+		StartTime: v12.Time{Time: time.Now()},
+	})
+
+	return func(result string, err error) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		step := &r.steps[index]
+		step.Result = result
+		step.EndTime = v12.Time{Time: time.Now()}
+		step.Duration = step.EndTime.Sub(step.StartTime.Time).Round(time.Millisecond).String()
+		if err != nil {
+			step.Message = stepErrorCause(step.Path, err).Error()
+		}
+	}
+}
+
+// Report returns a copy of the collected step results in the order the steps have been started
+func (r *ExecutionReportCollector) Report() types.ExecutionReport {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	report := types.ExecutionReport{Steps: make([]types.StepExecutionReport, len(r.steps))}
+	for i := range r.steps {
+		r.steps[i].DeepCopyInto(&report.Steps[i])
+	}
+	return report
+}
+
+func GetExecutionReportCollector(ctx ExecutionContext) *ExecutionReportCollector {
+	return ExecutionReportKey.Get(ctx)
+}
+
+// recordStep starts the step record if the report is collected
+func recordStep(ctx ExecutionContext) func(result string, err error) {
+	if collector := GetExecutionReportCollector(ctx); collector != nil {
+		return collector.begin(GetStepPath(ctx))
+	}
+	return func(result string, err error) {}
+}
+
+// recordedResult converts the step outcome to the report result
+func recordedResult(executed bool, err error) string {
+	if err != nil {
+		return StepFailed
+	}
+	if executed {
+		return StepExecuted
+	}
+	return StepSkipped
+}
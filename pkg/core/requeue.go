@@ -0,0 +1,73 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// RequeuePolicy decides when the CR is reconciled again after the reconcile cycle
+type RequeuePolicy struct {
+	// FailureBackoff is the delay after the first failed cycle
+	FailureBackoff time.Duration
+	// Multiplier increases the delay after every consecutive failure, 1 is used if not set
+	Multiplier float64
+	// MaxBackoff limits the delay after failures, 24 hours is used if not set
+	MaxBackoff time.Duration
+	// ResyncInterval requeues successfully reconciled CRs periodically, zero disables the resync
+	ResyncInterval time.Duration
+	// Immediate decides whether the failure is requeued without delay, no failures are if not set
+	Immediate func(err error) bool
+	// ImmediateAttempts limits the immediate requeues of consecutive failures, then the backoff is applied
+	ImmediateAttempts int
+}
+
+// DefaultRequeuePolicy retries failed cycles from 30 seconds to 10 minutes,
+// transient failures recognized by IsRetryableError are retried immediately up to 3 times
+func DefaultRequeuePolicy() RequeuePolicy {
+	return RequeuePolicy{
+		FailureBackoff:    30 * time.Second,
+		Multiplier:        2,
+		MaxBackoff:        10 * time.Minute,
+		Immediate:         IsRetryableError,
+		ImmediateAttempts: 3,
+	}
+}
+
+// OnFailure returns the result for the failed cycle, failures is the number of consecutive failures including this one
+func (p RequeuePolicy) OnFailure(failures int, err error) reconcile.Result {
+	if p.Immediate != nil && failures <= p.ImmediateAttempts && p.Immediate(err) {
+		return reconcile.Result{Requeue: true}
+	}
+	if p.FailureBackoff <= 0 {
+		return reconcile.Result{}
+	}
+
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+	backoffAttempt := failures - 1
+	if p.Immediate != nil && p.Immediate(err) {
+		// immediate attempts are not counted in the backoff
+		backoffAttempt -= p.ImmediateAttempts
+	}
+	if backoffAttempt < 0 {
+		backoffAttempt = 0
+	}
+	delay := float64(p.FailureBackoff) * math.Pow(multiplier, float64(backoffAttempt))
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 24 * time.Hour
+	}
+	if delay > float64(maxBackoff) {
+		delay = float64(maxBackoff)
+	}
+	return reconcile.Result{RequeueAfter: time.Duration(delay)}
+}
+
+// OnSuccess returns the result for the succeeded cycle
+func (p RequeuePolicy) OnSuccess() reconcile.Result {
+	return reconcile.Result{RequeueAfter: p.ResyncInterval}
+}
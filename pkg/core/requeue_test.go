@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// requeueTestReconciler keeps the next retry time of the Pod CR in its start time
+type requeueTestReconciler struct {
+	testServiceReconciler
+}
+
+func (r *requeueTestReconciler) UpdateNextRetryTime(nextRetry *metav1.Time) {
+	r.instance.Status.StartTime = nextRetry
+}
+
+func TestRequeuePolicy(t *testing.T) {
+	policy := DefaultRequeuePolicy()
+	failure := errors.New("failed")
+	transient := &RetryableError{Err: failure}
+
+	assert.Equal(t, 30*time.Second, policy.OnFailure(1, failure).RequeueAfter)
+	assert.Equal(t, 2*time.Minute, policy.OnFailure(3, failure).RequeueAfter)
+	assert.Equal(t, 10*time.Minute, policy.OnFailure(20, failure).RequeueAfter)
+
+	assert.True(t, policy.OnFailure(3, transient).Requeue)
+	assert.Equal(t, 30*time.Second, policy.OnFailure(4, transient).RequeueAfter)
+
+	assert.Equal(t, time.Duration(0), policy.OnSuccess().RequeueAfter)
+	policy.ResyncInterval = time.Hour
+	assert.Equal(t, time.Hour, policy.OnSuccess().RequeueAfter)
+}
+
+func TestRequeueAfterFailure(t *testing.T) {
+	failures := 2
+	executions := 0
+	step := &testStep{executeFunc: func(ctx ExecutionContext) error {
+		executions++
+		if failures > 0 {
+			failures--
+			return errors.New("failed")
+		}
+		return nil
+	}}
+	r, kubeClient := newTestReconcileService(step)
+	r.Reconciler = &requeueTestReconciler{}
+
+	result, err := r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, executions)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+	stored := getTestCR(t, kubeClient)
+	assert.Equal(t, "Failed", stored.Status.Message)
+	assert.NotNil(t, stored.Status.StartTime)
+
+	// The spec is not changed, the retry runs the pipeline again with the longer backoff
+	result, err = r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, executions)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+
+	result, err = r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, executions)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+	stored = getTestCR(t, kubeClient)
+	assert.Equal(t, "Successful", stored.Status.Message)
+	assert.Nil(t, stored.Status.StartTime)
+
+	// The resync without changes does not run the pipeline
+	_, err = r.Reconcile(context.Background(), testRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, executions)
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"go.uber.org/zap"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// RetryPolicy describes how a failed operation is repeated
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, values less than 1 mean a single attempt
+	Attempts int
+	// Backoff is the delay before the second attempt
+	Backoff time.Duration
+	// Multiplier increases the delay after every attempt, 1 is used if not set
+	Multiplier float64
+	// MaxBackoff limits the delay between attempts if set
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the delay which is randomly added to it, e.g. 0.1
+	Jitter float64
+	// Retryable decides whether the error is transient, IsRetryableError is used if not set
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy retries transient errors 5 times within about half a minute
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:   5,
+		Backoff:    time.Second,
+		Multiplier: 2,
+		MaxBackoff: 30 * time.Second,
+		Jitter:     0.1,
+	}
+}
+
+// RetryAll treats every error as a transient one
+func RetryAll(err error) bool {
+	return true
+}
+
+// Delay returns the delay before the next attempt, attempt starts from 1
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+	delay := float64(p.Backoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+func (p RetryPolicy) isRetryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// Do calls the operation until it succeeds, fails with a non-retryable error or the attempts are exhausted.
+// Panics of the operation are handled as errors. The logger may be nil.
+func (p RetryPolicy) Do(logger *zap.Logger, name string, operation func() error) error {
+	return p.DoWithContext(context.Background(), logger, name, operation)
+}
+
+// DoWithContext is Do which stops waiting for the next attempt when the context is done
+func (p RetryPolicy) DoWithContext(ctx context.Context, logger *zap.Logger, name string, operation func() error) error {
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = callRecovered(operation)
+		if err == nil {
+			return nil
+		}
+		if !p.isRetryable(err) {
+			return err
+		}
+		if attempt == attempts {
+			break
+		}
+		delay := p.Delay(attempt)
+		if logger != nil {
+			logger.Warn(fmt.Sprintf("Attempt %d/%d of %s failed, retrying in %v, err: %v", attempt, attempts, name, delay, err))
+		}
+		if sleepErr := sleepWithContext(ctx, delay); sleepErr != nil {
+			return &ExecutionError{Msg: fmt.Sprintf("Retries of %s are aborted: %v, last error: %v", name, sleepErr, err), Err: err}
+		}
+	}
+
+	return &ExecutionError{Msg: fmt.Sprintf("All %d attempts of %s failed, last error: %v", attempts, name, err), Err: err}
+}
+
+// RetryableError marks the error as transient for IsRetryableError
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+var consulStatusCodeRegexp = regexp.MustCompile(`Unexpected response code: (\d{3})`)
+
+// IsRetryableError reports whether the error is a transient Kubernetes, Vault, Consul or network failure.
+// Conflicts are not retryable, repeating the call with the same stale object conflicts again.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var retryable *RetryableError
+	if errors.As(err, &retryable) {
+		return true
+	}
+
+	if k8sErrors.IsServerTimeout(err) || k8sErrors.IsTimeout(err) || k8sErrors.IsTooManyRequests(err) ||
+		k8sErrors.IsServiceUnavailable(err) || k8sErrors.IsInternalError(err) || k8sErrors.IsUnexpectedServerError(err) {
+		return true
+	}
+
+	var vaultErr *api.ResponseError
+	if errors.As(err, &vaultErr) {
+		return isRetryableStatusCode(vaultErr.StatusCode)
+	}
+
+	// consul client doesn't provide typed errors
+	if match := consulStatusCodeRegexp.FindStringSubmatch(err.Error()); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return isRetryableStatusCode(code)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isRetryableStatusCode(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// RetryStep is a decorator which repeats execution of the wrapped step according to the policy
+type RetryStep struct {
+	Executable
+	Policy RetryPolicy
+}
+
+func WithRetry(step Executable, policy RetryPolicy) *RetryStep {
+	return &RetryStep{Executable: step, Policy: policy}
+}
+
+func (r *RetryStep) Execute(ctx ExecutionContext) error {
+	name := GetStepPath(ctx)
+	if name == "" {
+		name = GetStepName(r)
+	}
+	return r.Policy.DoWithContext(GetContext(ctx), LoggerKey.Get(ctx), name, func() error {
+		return r.Executable.Execute(ctx)
+	})
+}
+
+func (r *RetryStep) Unwrap() Executable {
+	return r.Executable
+}
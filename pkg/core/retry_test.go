@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWithRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Multiplier: 2}
+
+	t.Run("Transient failures", func(t *testing.T) {
+		calls := 0
+		step := WithRetry(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			calls++
+			if calls < 3 {
+				return &RetryableError{Err: errors.New("not ready")}
+			}
+			return nil
+		}}, policy)
+		assert.Nil(t, step.Execute(NewDefaultExecutionContext()))
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, "testStep", GetStepName(step))
+	})
+
+	t.Run("Non-retryable failure", func(t *testing.T) {
+		calls := 0
+		step := WithRetry(&testStep{executeFunc: func(ctx ExecutionContext) error {
+			calls++
+			panic("invalid configuration")
+		}}, policy)
+		assert.EqualError(t, step.Execute(NewDefaultExecutionContext()), "invalid configuration")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		cause := k8sErrors.NewServiceUnavailable("unavailable")
+		err := policy.Do(nil, "operation", func() error { return cause })
+		assert.True(t, errors.Is(err, cause))
+	})
+}
+
+func TestIsRetryableError(t *testing.T) {
+	assert.True(t, IsRetryableError(k8sErrors.NewServiceUnavailable("unavailable")))
+	assert.False(t, IsRetryableError(k8sErrors.NewConflict(schema.GroupResource{Resource: "pods"}, "pod", errors.New("conflict"))))
+	assert.False(t, IsRetryableError(k8sErrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "pod")))
+	assert.True(t, IsRetryableError(fmt.Errorf("write failed: %w", &api.ResponseError{StatusCode: 503})))
+	assert.False(t, IsRetryableError(&api.ResponseError{StatusCode: 403}))
+	assert.True(t, IsRetryableError(errors.New("Unexpected response code: 500 (rpc error)")))
+	assert.False(t, IsRetryableError(errors.New("Unexpected response code: 400 (bad request)")))
+	assert.False(t, IsRetryableError(errors.New("invalid configuration")))
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	RollbackSucceeded = "succeeded"
+	RollbackFailed    = "failed"
+)
+
+// RollbackError is returned by a compound which has compensated its completed steps after a failure
+type RollbackError struct {
+	// Err is the failure which caused the rollback
+	Err             error
+	RolledBackSteps []string
+	RollbackErrors  []error
+}
+
+func (r *RollbackError) Error() string {
+	msg := fmt.Sprintf("%s\nRollback of steps [%s] is performed", r.Err.Error(), strings.Join(r.RolledBackSteps, ", "))
+	if len(r.RollbackErrors) > 0 {
+		msg += " with errors: " + NewAggregatedError(r.RollbackErrors).Error()
+	}
+	return msg
+}
+
+func (r *RollbackError) Unwrap() []error {
+	return append([]error{r.Err}, r.RollbackErrors...)
+}
+
+func (r *RollbackError) Succeeded() bool {
+	return len(r.RollbackErrors) == 0
+}
+
+// rollbackTrackingKey marks the context of the compound with enabled RollbackOnFailure,
+// nested compounds record their completed steps for the compensation as well
+var rollbackTrackingKey = NewKey[bool](constants.ContextRollbackTracking)
+
+func isRollbackTracked(ctx ExecutionContext) bool {
+	return rollbackTrackingKey.Get(ctx)
+}
+
+// compensator is implemented by compounds, their completed steps are compensated by the parent compound
+type compensator interface {
+	compensate(ctx ExecutionContext) ([]string, []error)
+}
+
+// executeWithRollback runs the steps with run and records the completed ones.
+// run may call stepFunc concurrently, the steps are compensated in reverse order of their completion.
+// Nested compounds are compensated as well, including the failed one which has completed a part of its steps.
+// Compounds without RollbackOnFailure only record the steps, they are compensated by the parent.
+func (r *DefaultCompound) executeWithRollback(ctx ExecutionContext,
+	run func(stepFunc func(stepCtx ExecutionContext, step Executable) error) error) error {
+	r.completedSteps = nil
+	ctx = withValue(ctx, rollbackTrackingKey.Name(), true)
+	err := run(func(stepCtx ExecutionContext, element Executable) error {
+		stepCtx = withValue(stepCtx, rollbackTrackingKey.Name(), true)
+		var executed bool
+		err := callRecovered(func() (stepErr error) {
+			executed, stepErr = runStep(stepCtx, element)
+			return
+		})
+		_, isCompound := UnwrapStep(element).(compensator)
+		if executed && (err == nil || isCompound) {
+			r.completedMutex.Lock()
+			r.completedSteps = append(r.completedSteps, element)
+			r.completedMutex.Unlock()
+		}
+		return err
+	})
+	if err == nil || !r.RollbackOnFailure {
+		return err
+	}
+
+	rollbackErr := &RollbackError{Err: err}
+	if inner, ok := err.(*RollbackError); ok {
+		rollbackErr = inner
+	}
+	rolledBack, rollbackErrs := r.rollbackCompletedSteps(ctx)
+	rollbackErr.RolledBackSteps = append(rollbackErr.RolledBackSteps, rolledBack...)
+	rollbackErr.RollbackErrors = append(rollbackErr.RollbackErrors, rollbackErrs...)
+	return rollbackErr
+}
+
+// Rollback compensates the steps completed during the last execution of the compound
+func (r *DefaultCompound) Rollback(ctx ExecutionContext) error {
+	_, errs := r.compensate(ctx)
+	return NewAggregatedError(errs)
+}
+
+func (r *DefaultCompound) compensate(ctx ExecutionContext) ([]string, []error) {
+	return r.rollbackCompletedSteps(ctx)
+}
+
+func (r *DefaultCompound) rollbackCompletedSteps(ctx ExecutionContext) ([]string, []error) {
+	var rolledBack []string
+	var errs []error
+	for i := len(r.completedSteps) - 1; i >= 0; i-- {
+		element := r.completedSteps[i]
+		if compound, ok := UnwrapStep(element).(compensator); ok {
+			compoundRolledBack, compoundErrs := compound.compensate(ctx)
+			rolledBack = append(rolledBack, compoundRolledBack...)
+			errs = append(errs, compoundErrs...)
+			continue
+		}
+		rollbackable, ok := UnwrapStep(element).(Rollbackable)
+		if !ok {
+			continue
+		}
+
+		stepName := GetStepName(element)
+		if logger, ok := LoggerKey.Lookup(ctx); ok {
+			logger.Info(fmt.Sprintf("Rolling back step %s", stepName))
+		}
+		err := callRecovered(func() error { return rollbackable.Rollback(ctx) })
+		if err != nil {
+			errs = append(errs, &ExecutionError{Msg: fmt.Sprintf("Rollback of step %s failed: %v", stepName, err), Err: err})
+		} else {
+			rolledBack = append(rolledBack, stepName)
+		}
+	}
+	r.completedSteps = nil
+	return rolledBack, errs
+}
+
+// NewRollbackStatus returns nil if no rollback has been performed for the error
+func NewRollbackStatus(err error) *types.RollbackStatus {
+	var rollbackErr *RollbackError
+	if !errors.As(err, &rollbackErr) {
+		return nil
+	}
+
+	status := &types.RollbackStatus{
+		Status:             RollbackSucceeded,
+		Steps:              rollbackErr.RolledBackSteps,
+		LastTransitionTime: v12.Time{Time: time.Now()},
+	}
+	if !rollbackErr.Succeeded() {
+		status.Status = RollbackFailed
+		status.Message = NewAggregatedError(rollbackErr.RollbackErrors).Error()
+	}
+	return status
+}
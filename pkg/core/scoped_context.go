@@ -0,0 +1,58 @@
+package core
+
+import (
+	"sync"
+)
+
+// ScopedExecutionContext is a child scope of the execution context.
+// Variables which are not set in the scope are read from the parent context,
+// while writes stay local until they are exported with Export.
+type ScopedExecutionContext struct {
+	ExecutionContext
+	name       string
+	scopedVars map[string]interface{}
+	mutex      sync.RWMutex
+}
+
+func NewScopedExecutionContext(parent ExecutionContext, name string) *ScopedExecutionContext {
+	return &ScopedExecutionContext{
+		ExecutionContext: parent,
+		name:             name,
+		scopedVars:       make(map[string]interface{}),
+	}
+}
+
+func (r *ScopedExecutionContext) Name() string {
+	return r.name
+}
+
+func (r *ScopedExecutionContext) Parent() ExecutionContext {
+	return r.ExecutionContext
+}
+
+func (r *ScopedExecutionContext) Set(str string, obj interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.scopedVars[str] = obj
+}
+
+func (r *ScopedExecutionContext) Get(str string) interface{} {
+	r.mutex.RLock()
+	obj, ok := r.scopedVars[str]
+	r.mutex.RUnlock()
+	if ok {
+		return obj
+	}
+	return r.ExecutionContext.Get(str)
+}
+
+// Export copies the variables set in the scope to the parent context
+func (r *ScopedExecutionContext) Export(keys ...string) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, key := range keys {
+		if obj, ok := r.scopedVars[key]; ok {
+			r.ExecutionContext.Set(key, obj)
+		}
+	}
+}
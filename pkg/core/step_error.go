@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// StepError is a failure of the step with its path in the executable tree.
+// Stack is set if the step has panicked.
+type StepError struct {
+	Path  string
+	Err   error
+	Stack string
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("Step %s failed: %v", e.Path, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// wrapStepError binds the error to the current step unless it's already bound to a nested step
+func wrapStepError(ctx ExecutionContext, err error) error {
+	if err == nil {
+		return nil
+	}
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		return err
+	}
+	return &StepError{Path: GetStepPath(ctx), Err: err}
+}
+
+func newPanicStepError(ctx ExecutionContext, recovered interface{}) error {
+	return &StepError{Path: GetStepPath(ctx), Err: PanicToError(recovered), Stack: string(debug.Stack())}
+}
+
+// callStep calls the step function converting its panics and errors to StepError
+func callStep(ctx ExecutionContext, stepFunc func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = newPanicStepError(ctx, recovered)
+		}
+	}()
+	return wrapStepError(ctx, stepFunc())
+}
+
+// stepErrorCause returns the error without the StepError of the current step
+func stepErrorCause(path string, err error) error {
+	var stepErr *StepError
+	if errors.As(err, &stepErr) && stepErr.Path == path {
+		return stepErr.Err
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+)
+
+const StepPathSeparator = "/"
+
+// GetStepPath returns the path of the step which is currently handled, e.g. FakeServicesCompound/Fake/CreatePVCStep
+func GetStepPath(ctx ExecutionContext) string {
+	return StepPathKey.Get(ctx)
+}
+
+func withStepPath(ctx ExecutionContext, segment string) ExecutionContext {
+	path := GetStepPath(ctx)
+	if path == "" {
+		path = segment
+	} else {
+		path = path + StepPathSeparator + segment
+	}
+	return withValue(ctx, constants.ContextStepPath, path)
+}
+
+// stepPathSegments returns unique names of the steps within a compound.
+// Repeated step types get an occurrence index, e.g. CreatePVCStep, CreatePVCStep#1
+func stepPathSegments(steps []Executable) []string {
+	segments := make([]string, len(steps))
+	occurrences := map[string]int{}
+	for i, element := range steps {
+		name := GetStepName(element)
+		if count := occurrences[name]; count > 0 {
+			segments[i] = fmt.Sprintf("%s#%d", name, count)
+		} else {
+			segments[i] = name
+		}
+		occurrences[name]++
+	}
+	return segments
+}
+
+// valueOverlayContext overrides a single variable of the parent context without changing the parent.
+// All other variables are read from and written to the parent context.
+type valueOverlayContext struct {
+	ExecutionContext
+	key   string
+	value interface{}
+}
+
+func withValue(ctx ExecutionContext, key string, value interface{}) ExecutionContext {
+	return &valueOverlayContext{ExecutionContext: ctx, key: key, value: value}
+}
+
+func (r *valueOverlayContext) Get(str string) interface{} {
+	if str == r.key {
+		return r.value
+	}
+	return r.ExecutionContext.Get(str)
+}
+
+func (r *valueOverlayContext) Set(str string, obj interface{}) {
+	if str == r.key {
+		r.value = obj
+		return
+	}
+	r.ExecutionContext.Set(str, obj)
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startStepSpan starts the span of the step and returns the context which nested steps and helpers use as the parent
+func startStepSpan(ctx ExecutionContext, element Executable) (ExecutionContext, trace.Span) {
+	stepName := GetStepName(element)
+	goCtx, span := tracing.StartSpan(GetContext(ctx), stepName,
+		tracing.StepNameKey.String(stepName),
+		tracing.StepPathKey.String(GetStepPath(ctx)),
+		tracing.DeployTypeKey.String(string(ServiceDeployTypeKey.Get(ctx))),
+	)
+	return withValue(ctx, GoContextKey.Name(), goCtx), span
+}
+
+func endStepSpan(span trace.Span, result string, err error) {
+	span.SetAttributes(tracing.StepResultKey.String(result))
+	tracing.EndSpan(span, err)
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestStepTracing(t *testing.T) {
+	exporter := tracing.SetupInMemory()
+
+	root := &DefaultCompound{}
+	root.AddStep(&testStep{})
+	root.AddStep(&testStep{executeFunc: func(ctx ExecutionContext) error {
+		return errors.New("deployment failed")
+	}})
+
+	executor := DefaultExecutor()
+	executor.SetExecutable(root)
+	assert.NotNil(t, executor.Execute(NewDefaultExecutionContext()))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+	rootSpan := spans[len(spans)-1]
+	assert.Equal(t, "DefaultCompound", rootSpan.Name)
+	for _, span := range spans[:2] {
+		assert.Equal(t, "testStep", span.Name)
+		assert.Equal(t, rootSpan.SpanContext.SpanID(), span.Parent.SpanID())
+	}
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Contains(t, spans[1].Attributes, tracing.StepPathKey.String("DefaultCompound/testStep#1"))
+	assert.Contains(t, spans[1].Attributes, tracing.StepResultKey.String(StepFailed))
+}
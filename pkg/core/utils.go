@@ -105,6 +105,12 @@ func CreateOrUpdateK8sObject(kuberClient client.Client, scheme *runtime.Scheme,
 }
 
 func CreateOrUpdateRuntimeObjectAndWait(kuberClient client.Client, scheme *runtime.Scheme, owner v12.Object,
+	object client.Object, meta v12.ObjectMeta, forceUpdate, waitResult bool) error {
+	return CreateOrUpdateRuntimeObjectAndWaitContext(context.Background(), kuberClient, scheme, owner, object, meta, forceUpdate, waitResult)
+}
+
+// CreateOrUpdateRuntimeObjectAndWaitContext is CreateOrUpdateRuntimeObjectAndWait which requests and waits are cancelled with the context
+func CreateOrUpdateRuntimeObjectAndWaitContext(ctx context.Context, kuberClient client.Client, scheme *runtime.Scheme, owner v12.Object,
 	object client.Object, meta v12.ObjectMeta, forceUpdate, waitResult bool) error {
 	// Set reference.
 	if owner != nil {
@@ -114,11 +120,11 @@ func CreateOrUpdateRuntimeObjectAndWait(kuberClient client.Client, scheme *runti
 		}
 	}
 	emptyObject := Zero(object).(client.Object)
-	err := kuberClient.Get(context.TODO(), types.NamespacedName{
+	err := kuberClient.Get(ctx, types.NamespacedName{
 		Name: meta.Name, Namespace: meta.Namespace,
 	}, emptyObject)
 	if err != nil && errors.IsNotFound(err) {
-		err = kuberClient.Create(context.TODO(), object)
+		err = kuberClient.Create(ctx, object)
 		if err != nil {
 			newJsonString, errJson := json.MarshalIndent(object, "", "  ")
 			if errJson != nil {
@@ -133,8 +139,8 @@ func CreateOrUpdateRuntimeObjectAndWait(kuberClient client.Client, scheme *runti
 		}
 
 		if waitResult {
-			return wait.PollImmediate(time.Second, time.Second*10, func() (bool, error) {
-				err := kuberClient.Get(context.TODO(), types.NamespacedName{
+			return wait.PollUntilContextTimeout(ctx, time.Second, time.Second*10, true, func(ctx context.Context) (bool, error) {
+				err := kuberClient.Get(ctx, types.NamespacedName{
 					Name: meta.Name, Namespace: meta.Namespace,
 				}, emptyObject)
 
@@ -144,7 +150,7 @@ func CreateOrUpdateRuntimeObjectAndWait(kuberClient client.Client, scheme *runti
 		}
 	} else {
 		if !reflect.DeepEqual(emptyObject, object) {
-			err = kuberClient.Update(context.TODO(), object)
+			err = kuberClient.Update(ctx, object)
 			if err != nil && forceUpdate {
 				existedObjectJsonString := objectToYaml(emptyObject)
 				newJsonString := objectToYaml(object)
@@ -158,8 +164,8 @@ func CreateOrUpdateRuntimeObjectAndWait(kuberClient client.Client, scheme *runti
 			}
 
 			if waitResult {
-				return wait.PollImmediate(time.Second, time.Second*10, func() (bool, error) {
-					err := kuberClient.Get(context.TODO(), types.NamespacedName{
+				return wait.PollUntilContextTimeout(ctx, time.Second, time.Second*10, true, func(ctx context.Context) (bool, error) {
+					err := kuberClient.Get(ctx, types.NamespacedName{
 						Name: meta.Name, Namespace: meta.Namespace,
 					}, emptyObject)
 
@@ -294,25 +300,23 @@ func GetLogger(debug bool) *zap.Logger {
 }
 
 func AddServiceDeployResultToContext(ctx ExecutionContext, serviceName string, result string) {
-	iInfo := ctx.Get(constants.ContextServiceDeploymentInfo)
-
-	info := map[string]string{}
-	if iInfo != nil {
-		info = iInfo.(map[string]string)
+	info, ok := ServiceDeploymentInfoKey.Lookup(ctx)
+	if !ok {
+		info = map[string]string{}
 	}
 
 	info[serviceName] = result
-	ctx.Set(constants.ContextServiceDeploymentInfo, info)
+	ServiceDeploymentInfoKey.Set(ctx, info)
 }
 
+// GetMicroServiceDeployType returns CleanDeploy for the service which previous deployment has failed,
+// use GetMicroServiceDeployTypeWithCleanup to get CleanupRequired instead
 func GetMicroServiceDeployType(ctx ExecutionContext, serviceName string) MicroServiceDeployType {
-	iInfo := ctx.Get(constants.ContextServiceDeploymentInfo)
+	info, ok := ServiceDeploymentInfoKey.Lookup(ctx)
 
-	if iInfo == nil {
+	if !ok {
 		return CleanDeploy
 	} else {
-		info := iInfo.(map[string]string)
-
 		serviceStatus := info[serviceName]
 
 		if serviceStatus == "" {
@@ -320,26 +324,32 @@ func GetMicroServiceDeployType(ctx ExecutionContext, serviceName string) MicroSe
 		} else if serviceStatus == constants.MicroServiceSuccessDeploymentResult {
 			return Update
 		} else {
-			//return CleanupRequired
 			return CleanDeploy
 		}
 	}
 }
 
-func GetCurrentDeployType(ctx ExecutionContext) MicroServiceDeployType {
-	iCurrent := ctx.Get(constants.ContextServiceDeployType)
+// GetMicroServiceDeployTypeWithCleanup is GetMicroServiceDeployType which returns CleanupRequired
+// for the service which previous deployment has failed. The steps checking for CleanDeploy
+// have to run in CleanupRequired as well to be used with it.
+func GetMicroServiceDeployTypeWithCleanup(ctx ExecutionContext, serviceName string) MicroServiceDeployType {
+	deployType := GetMicroServiceDeployType(ctx, serviceName)
+	if info, ok := ServiceDeploymentInfoKey.Lookup(ctx); ok && deployType == CleanDeploy && info[serviceName] != "" {
+		return CleanupRequired
+	}
+	return deployType
+}
 
-	current := Empty
-	if iCurrent != nil {
-		current = iCurrent.(MicroServiceDeployType)
-	} else {
+func GetCurrentDeployType(ctx ExecutionContext) MicroServiceDeployType {
+	current, ok := ServiceDeployTypeKey.Lookup(ctx)
+	if !ok {
 		SetCurrentDeployType(ctx, current)
 	}
 	return current
 }
 
 func SetCurrentDeployType(ctx ExecutionContext, deployType MicroServiceDeployType) {
-	ctx.Set(constants.ContextServiceDeployType, deployType)
+	ServiceDeployTypeKey.Set(ctx, deployType)
 }
 
 func HandleError(err error, log func(msg string, fields ...zap.Field), message string) {
@@ -351,7 +361,7 @@ func HandleError(err error, log func(msg string, fields ...zap.Field), message s
 func PanicError(err error, log func(msg string, fields ...zap.Field), message string) {
 	HandleError(err, log, message)
 	if err != nil {
-		panic(&ExecutionError{Msg: fmt.Sprintf("%s\n%s", message, err.Error())})
+		panic(&ExecutionError{Msg: fmt.Sprintf("%s\n%s", message, err.Error()), Err: err})
 	}
 }
 
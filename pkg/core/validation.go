@@ -0,0 +1,93 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError is a validation failure of the CR field, Field is the path like spec.storage.size
+type FieldError struct {
+	Field   string
+	Message string
+	// Step is the path of the step which has reported the error, it's set by the compound
+	Step string
+	// Err is an optional cause of the error
+	Err error
+}
+
+func NewFieldError(field string, message string) *FieldError {
+	return &FieldError{Field: field, Message: message}
+}
+
+func (r *FieldError) Error() string {
+	if r.Field == "" {
+		return r.Message
+	}
+	return r.Field + ": " + r.Message
+}
+
+func (r *FieldError) Unwrap() error {
+	return r.Err
+}
+
+// ValidationError holds validation failures of all steps
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (r *ValidationError) Error() string {
+	messages := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("Validation failed with %d error(s): %s", len(r.Errors), strings.Join(messages, "; "))
+}
+
+func (r *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Add appends the error of the step, nested validation errors are flattened
+// and other errors are converted to FieldError without field
+func (r *ValidationError) Add(step string, err error) {
+	if err == nil {
+		return
+	}
+	var validationErr *ValidationError
+	var fieldErr *FieldError
+	switch {
+	case errors.As(err, &validationErr):
+		for _, nested := range validationErr.Errors {
+			r.Add(step, nested)
+		}
+	case errors.As(err, &fieldErr):
+		if fieldErr.Step == "" {
+			fieldErr.Step = step
+		}
+		r.Errors = append(r.Errors, fieldErr)
+	default:
+		r.Errors = append(r.Errors, &FieldError{Message: err.Error(), Step: step, Err: err})
+	}
+}
+
+// ErrorOrNil returns nil if there are no errors
+func (r *ValidationError) ErrorOrNil() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r
+}
+
+// NewValidationError returns nil if there are no errors in the list
+func NewValidationError(errs ...error) error {
+	result := &ValidationError{}
+	for _, err := range errs {
+		result.Add("", err)
+	}
+	return result.ErrorOrNil()
+}
@@ -6,9 +6,13 @@ import (
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/steps"
-	"go.uber.org/zap"
 	v12 "k8s.io/api/core/v1"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	specKey        = core.NewKey[*FakeService](constants.ContextSpec)
+	pvcNamesKey    = core.NewKey[[]string]("pvcNames")
+	pvNodeNamesKey = core.NewKey[[]map[string]string]("pvNodeNames")
 )
 
 type Fake struct {
@@ -20,17 +24,18 @@ type FakeBuilder struct {
 }
 
 func (r *FakeBuilder) Build(ctx core.ExecutionContext) core.Executable {
-	spec := ctx.Get(constants.ContextSpec).(*FakeService)
+	spec := specKey.MustGet(ctx)
 	pvcSelector := map[string]string{
 		"name": "Fake",
 	}
 
 	fake := Fake{}
 	fake.ServiceName = "Fake"
+	fake.ExportVars = []string{"password"}
 	fake.CalcDeployType = func(ctx core.ExecutionContext) (core.MicroServiceDeployType, error) {
-		request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-		log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-		helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
+		request := core.RequestKey.MustGet(ctx)
+		log := core.LoggerKey.MustGet(ctx)
+		helperImpl := core.GetKubernetesHelper(ctx)
 
 		pvcList := &v12.PersistentVolumeClaimList{}
 		err := helperImpl.ListRuntimeObjectsByLabels(pvcList, request.Namespace, pvcSelector)
@@ -50,8 +55,8 @@ func (r *FakeBuilder) Build(ctx core.ExecutionContext) core.Executable {
 		return result, err
 	}
 
-	pvcContext := fmt.Sprintf("pvcNames%v", 0)
-	nodesContext := fmt.Sprintf("pvNodeNames%v", 0)
+	pvcContext := pvcNamesKey.Name()
+	nodesContext := pvNodeNamesKey.Name()
 	storage := spec.Spec.Storage
 	fake.AddStep(&steps.CreatePVCStep{
 		Storage:           storage,
@@ -1,10 +1,7 @@
 package fake
 
 import (
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
-	"go.uber.org/zap"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type FakeServicesCompound struct {
@@ -17,8 +14,8 @@ type FakeServiceBuilder struct {
 
 func (r *FakeServiceBuilder) Build(ctx core.ExecutionContext) core.Executable {
 	//spec := ctx.Get(constants.ContextSpec).(*FakeService)
-	client := ctx.Get(constants.ContextClient).(client.Client)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	client := core.ClientKey.MustGet(ctx)
+	log := core.LoggerKey.MustGet(ctx)
 	log.Debug("Fake Executable build process is started")
 
 	defaultUtilsHelper := &core.DefaultKubernetesHelperImpl{
@@ -1,14 +1,8 @@
 package fake
 
 import (
-	"fmt"
-
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
-	"go.uber.org/zap"
-	"k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type FakeDeployment struct {
@@ -16,16 +10,16 @@ type FakeDeployment struct {
 }
 
 func (r *FakeDeployment) Execute(ctx core.ExecutionContext) error {
-	request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-	helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	scheme := ctx.Get(constants.ContextSchema).(*runtime.Scheme)
-	spec := ctx.Get(constants.ContextSpec).(*FakeService)
+	request := core.RequestKey.MustGet(ctx)
+	helperImpl := core.GetKubernetesHelper(ctx)
+	log := core.LoggerKey.MustGet(ctx)
+	scheme := core.SchemaKey.MustGet(ctx)
+	spec := specKey.MustGet(ctx)
 
 	log.Info("Fake Deployment initialization step started")
 
-	pvcNames := ctx.Get(fmt.Sprintf(fmt.Sprintf("pvcNames%v", 0))).([]string)
-	nodeLabels := ctx.Get(fmt.Sprintf("pvNodeNames%v", 0)).([]map[string]string)
+	pvcNames := pvcNamesKey.MustGet(ctx)
+	nodeLabels := pvNodeNamesKey.MustGet(ctx)
 
 	nodeSelector := map[string]string{}
 	if nodeLabels != nil &&
@@ -61,3 +55,11 @@ func (r *FakeDeployment) Execute(ctx core.ExecutionContext) error {
 func (r *FakeDeployment) Condition(ctx core.ExecutionContext) (bool, error) {
 	return true, nil
 }
+
+func (r *FakeDeployment) Requires() []string {
+	return []string{pvcNamesKey.Name(), pvNodeNamesKey.Name()}
+}
+
+func (r *FakeDeployment) Produces() []string {
+	return nil
+}
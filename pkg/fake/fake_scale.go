@@ -1,12 +1,9 @@
 package fake
 
 import (
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 
-	"go.uber.org/zap"
 	v12 "k8s.io/api/apps/v1"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type FakeScaleDeployment struct {
@@ -14,9 +11,9 @@ type FakeScaleDeployment struct {
 }
 
 func (r *FakeScaleDeployment) Execute(ctx core.ExecutionContext) error {
-	request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-	helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	request := core.RequestKey.MustGet(ctx)
+	helperImpl := core.GetKubernetesHelper(ctx)
+	log := core.LoggerKey.MustGet(ctx)
 
 	fakeName := "Fake"
 	dcList := &v12.DeploymentList{}
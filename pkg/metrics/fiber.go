@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"context"
+
+	coreFiber "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/fiber"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+const DefaultPath = "/metrics"
+
+// Handler serves Registry which contains the core metrics
+func Handler() fiber.Handler {
+	handler := fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+	return func(c *fiber.Ctx) error {
+		handler(c.Context())
+		return nil
+	}
+}
+
+// SetUp registers the metrics endpoint, it can be passed to FiberService.Create or called from another set up function
+func SetUp(app *fiber.App, ctx context.Context) error {
+	app.Get(DefaultPath, Handler())
+	return nil
+}
+
+// Serve starts the fiber server with the metrics endpoint on the port
+func Serve(port int, forceShutdown ...bool) error {
+	return (*coreFiber.GetFiberService()).Create(port, SetUp, forceShutdown...)
+}
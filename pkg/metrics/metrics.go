@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const Namespace = "qubership_operator"
+
+// Results of the reconcile cycles and steps
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+)
+
+// External systems called by the core
+const (
+	SystemVault  = "vault"
+	SystemConsul = "consul"
+)
+
+var (
+	ReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "reconcile_total",
+		Help:      "Number of reconcile cycles per custom resource and result",
+	}, []string{"namespace", "name", "result"})
+
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of reconcile cycles per custom resource",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"namespace", "name"})
+
+	StepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Duration of pipeline steps per step name and result",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"step", "result"})
+
+	StepFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "step_failures_total",
+		Help:      "Number of failed pipeline steps per step name",
+	}, []string{"step"})
+
+	SpecChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "spec_changes_total",
+		Help:      "Number of detected spec changes per custom resource and service",
+	}, []string{"namespace", "name", "service"})
+
+	PasswordRotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "password_rotations_total",
+		Help:      "Number of password rotations triggered by the credential informer",
+	}, []string{"namespace", "name"})
+
+	DeploymentVersionMismatch = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "deployment_version_mismatch",
+		Help:      "Set to 1 while the CR is left to the operator of another deployment version",
+	}, []string{"namespace", "name"})
+
+	DriftedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "drifted_objects",
+		Help:      "Number of managed objects which differ from the desired state on the last drift check",
+	}, []string{"namespace", "name"})
+
+	ExternalCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "external_call_duration_seconds",
+		Help:      "Latency of Vault and Consul calls",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"system", "operation"})
+
+	ExternalCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "external_call_errors_total",
+		Help:      "Number of failed Vault and Consul calls",
+	}, []string{"system", "operation"})
+)
+
+// Collectors returns all metrics of the operator core
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		ReconcileTotal, ReconcileDuration, StepDuration, StepFailures,
+		SpecChanges, PasswordRotations, DeploymentVersionMismatch, DriftedObjects, ExternalCallDuration, ExternalCallErrors,
+	}
+}
+
+// Registry contains the core metrics, it is served by Handler
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(Collectors()...)
+}
+
+// Register adds the core metrics to another registry, e.g. to the controller-runtime one
+// served on the manager metrics endpoint: metrics.Register(ctrlmetrics.Registry)
+func Register(registerer prometheus.Registerer) error {
+	for _, collector := range Collectors() {
+		if err := registerer.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailed
+	}
+	return ResultSuccess
+}
+
+func ObserveReconcile(namespace string, name string, start time.Time, err error) {
+	ReconcileTotal.WithLabelValues(namespace, name, result(err)).Inc()
+	ReconcileDuration.WithLabelValues(namespace, name).Observe(time.Since(start).Seconds())
+}
+
+// ObserveStep records the duration of the step, stepResult is one of the execution report results
+func ObserveStep(step string, stepResult string, start time.Time, err error) {
+	StepDuration.WithLabelValues(step, stepResult).Observe(time.Since(start).Seconds())
+	if err != nil {
+		StepFailures.WithLabelValues(step).Inc()
+	}
+}
+
+func SetDeploymentVersionMismatch(namespace string, name string, mismatch bool) {
+	value := 0.0
+	if mismatch {
+		value = 1
+	}
+	DeploymentVersionMismatch.WithLabelValues(namespace, name).Set(value)
+}
+
+func ObserveExternalCall(system string, operation string, start time.Time, err error) {
+	ExternalCallDuration.WithLabelValues(system, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ExternalCallErrors.WithLabelValues(system, operation).Inc()
+	}
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+)
+
+// Types of the core compounds
+const (
+	DefaultCompoundType      = "DefaultCompound"
+	ParallelCompoundType     = "ParallelCompound"
+	DAGCompoundType          = "DAGCompound"
+	MicroServiceCompoundType = "MicroServiceCompound"
+)
+
+type compoundParams struct {
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+	MaxWorkers        int  `json:"maxWorkers,omitempty"`
+}
+
+type microServiceParams struct {
+	compoundParams
+	ServiceName string   `json:"serviceName"`
+	ExportVars  []string `json:"exportVars,omitempty"`
+	// DeployType is calculated with core.GetMicroServiceDeployType if not set
+	DeployType core.MicroServiceDeployType `json:"deployType,omitempty"`
+	// CleanupOnFailure calculates the deploy type with core.GetMicroServiceDeployTypeWithCleanup
+	CleanupOnFailure bool `json:"cleanupOnFailure,omitempty"`
+}
+
+func registerCompounds(registry *Registry) {
+	registry.Register(DefaultCompoundType, func(ctx core.ExecutionContext, params Params) (core.Executable, error) {
+		p := compoundParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return &core.DefaultCompound{RollbackOnFailure: p.RollbackOnFailure}, nil
+	})
+	registry.Register(ParallelCompoundType, func(ctx core.ExecutionContext, params Params) (core.Executable, error) {
+		p := compoundParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		compound := &core.ParallelCompound{MaxWorkers: p.MaxWorkers}
+		compound.RollbackOnFailure = p.RollbackOnFailure
+		return compound, nil
+	})
+	registry.Register(DAGCompoundType, func(ctx core.ExecutionContext, params Params) (core.Executable, error) {
+		p := compoundParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		compound := &core.DAGCompound{MaxWorkers: p.MaxWorkers}
+		compound.RollbackOnFailure = p.RollbackOnFailure
+		return compound, nil
+	})
+	registry.Register(MicroServiceCompoundType, func(ctx core.ExecutionContext, params Params) (core.Executable, error) {
+		p := microServiceParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		if p.ServiceName == "" {
+			return nil, &core.ExecutionError{Msg: "serviceName parameter is required"}
+		}
+		compound := &core.MicroServiceCompound{
+			ServiceName: p.ServiceName,
+			ExportVars:  p.ExportVars,
+			CalcDeployType: func(ctx core.ExecutionContext) (core.MicroServiceDeployType, error) {
+				if p.DeployType != core.Empty {
+					return p.DeployType, nil
+				}
+				if p.CleanupOnFailure {
+					return core.GetMicroServiceDeployTypeWithCleanup(ctx, p.ServiceName), nil
+				}
+				return core.GetMicroServiceDeployType(ctx, p.ServiceName), nil
+			},
+		}
+		compound.RollbackOnFailure = p.RollbackOnFailure
+		return compound, nil
+	})
+}
@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+)
+
+// conditionalStep runs the wrapped step only if the CR matches the condition
+type conditionalStep struct {
+	core.Executable
+	when ConditionDefinition
+}
+
+func (r *conditionalStep) Condition(ctx core.ExecutionContext) (bool, error) {
+	matched, err := r.when.evaluate(ctx)
+	if err != nil || !matched {
+		return false, err
+	}
+	return r.Executable.Condition(ctx)
+}
+
+func (r *conditionalStep) Unwrap() core.Executable {
+	return r.Executable
+}
+
+func (c ConditionDefinition) evaluate(ctx core.ExecutionContext) (bool, error) {
+	fields, err := specFields(ctx)
+	if err != nil {
+		return false, err
+	}
+	value, found := lookupField(fields, c.Field)
+
+	var matched bool
+	if c.Equals != nil {
+		matched = found && fmt.Sprint(value) == fmt.Sprint(c.Equals)
+	} else {
+		matched = found && value != nil && !reflect.ValueOf(value).IsZero()
+	}
+	return matched != c.Not, nil
+}
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+)
+
+// StepDefinition describes a step or a compound of the declarative pipeline.
+// Parameters may reference fields of the CR with ${spec.field} placeholders.
+type StepDefinition struct {
+	// Type is the name the step factory is registered with, DefaultCompound is used if not set
+	Type string `json:"type,omitempty"`
+	// Name is only used in error messages
+	Name   string                 `json:"name,omitempty"`
+	When   *ConditionDefinition   `json:"when,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	// DeployTypes limit the step to the deploy types of the current service
+	DeployTypes []core.MicroServiceDeployType `json:"deployTypes,omitempty"`
+	// Timeout limits the step duration, e.g. 5m
+	Timeout string           `json:"timeout,omitempty"`
+	Steps   []StepDefinition `json:"steps,omitempty"`
+}
+
+// ConditionDefinition runs the step only if the CR field is set to a non-zero value
+// or is equal to Equals if it is specified
+type ConditionDefinition struct {
+	Field  string      `json:"field"`
+	Equals interface{} `json:"equals,omitempty"`
+	Not    bool        `json:"not,omitempty"`
+}
+
+func (d StepDefinition) displayName() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	if d.Type != "" {
+		return d.Type
+	}
+	return DefaultCompoundType
+}
@@ -0,0 +1,123 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"sigs.k8s.io/yaml"
+)
+
+// Parse reads the pipeline document in YAML or JSON format
+func Parse(data []byte) (*StepDefinition, error) {
+	definition := &StepDefinition{}
+	if err := yaml.UnmarshalStrict(data, definition); err != nil {
+		return nil, &core.ExecutionError{Msg: fmt.Sprintf("Failed to parse pipeline definition: %v", err), Err: err}
+	}
+	return definition, nil
+}
+
+func ParseFile(path string) (*StepDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Loader builds the executable tree from the pipeline definition
+type Loader struct {
+	Registry *Registry
+}
+
+func NewLoader(registry *Registry) *Loader {
+	return &Loader{Registry: registry}
+}
+
+// Build creates the steps with the parameters resolved against the CR of the execution context
+func (l *Loader) Build(ctx core.ExecutionContext, definition *StepDefinition) (core.Executable, error) {
+	fields, err := specFields(ctx)
+	if err != nil {
+		return nil, &core.ExecutionError{Msg: fmt.Sprintf("Failed to read CR fields: %v", err), Err: err}
+	}
+	return l.build(ctx, fields, *definition, definition.displayName())
+}
+
+func (l *Loader) build(ctx core.ExecutionContext, fields map[string]interface{}, definition StepDefinition, path string) (core.Executable, error) {
+	stepType := definition.Type
+	if stepType == "" {
+		stepType = DefaultCompoundType
+	}
+	factory, ok := l.Registry.Lookup(stepType)
+	if !ok {
+		return nil, &UnknownStepTypeError{Type: stepType}
+	}
+
+	resolved, err := resolveValue(fields, map[string]interface{}(definition.Params))
+	if err != nil {
+		return nil, wrapDefinitionError(path, err)
+	}
+	params, _ := resolved.(map[string]interface{})
+	step, err := factory(ctx, params)
+	if err != nil {
+		return nil, wrapDefinitionError(path, err)
+	}
+
+	if len(definition.Steps) > 0 {
+		compound, ok := step.(core.ExecutableCompound)
+		if !ok {
+			return nil, wrapDefinitionError(path, fmt.Errorf("step type %s can't have nested steps", stepType))
+		}
+		for _, child := range definition.Steps {
+			childStep, err := l.build(ctx, fields, child, path+"/"+child.displayName())
+			if err != nil {
+				return nil, err
+			}
+			compound.AddStep(childStep)
+		}
+	}
+
+	if definition.Timeout != "" {
+		timeout, err := time.ParseDuration(definition.Timeout)
+		if err != nil {
+			return nil, wrapDefinitionError(path, err)
+		}
+		step = core.WithTimeout(step, timeout)
+	}
+	if len(definition.DeployTypes) > 0 {
+		step = core.RunIn(step, definition.DeployTypes...)
+	}
+	if definition.When != nil {
+		step = &conditionalStep{Executable: step, when: *definition.When}
+	}
+	return step, nil
+}
+
+func wrapDefinitionError(path string, err error) error {
+	return &core.ExecutionError{Msg: fmt.Sprintf("Failed to build step %s: %v", path, err), Err: err}
+}
+
+// Builder is the core.ExecutableBuilder of the pipeline definition.
+// Build panics with the definition error, it's handled as a failed reconcile.
+type Builder struct {
+	Definition *StepDefinition
+	// Registry is DefaultRegistry if not set
+	Registry *Registry
+}
+
+func NewBuilder(definition *StepDefinition) *Builder {
+	return &Builder{Definition: definition}
+}
+
+func (b *Builder) Build(ctx core.ExecutionContext) core.Executable {
+	registry := b.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	step, err := NewLoader(registry).Build(ctx, b.Definition)
+	if err != nil {
+		panic(err)
+	}
+	return step
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type recordStep struct {
+	core.DefaultExecutable
+	Message  string `json:"message"`
+	Replicas int64  `json:"replicas,omitempty"`
+	records  *[]string
+}
+
+func (r *recordStep) Execute(ctx core.ExecutionContext) error {
+	*r.records = append(*r.records, r.Message)
+	return nil
+}
+
+const testPipeline = `
+type: DefaultCompound
+steps:
+  - type: MicroServiceCompound
+    params:
+      serviceName: ${metadata.name}
+      deployType: Update
+    steps:
+      - type: RecordStep
+        params:
+          message: "deploy ${metadata.name} to ${metadata.namespace}"
+          replicas: ${spec.replicas}
+      - type: RecordStep
+        when:
+          field: spec.backup.mode
+          equals: "enabled"
+        params:
+          message: backup
+  - type: RecordStep
+    when:
+      field: spec.tls
+    params:
+      message: tls
+`
+
+func TestLoader(t *testing.T) {
+	var records []string
+	var replicas []int64
+	registry := NewRegistry()
+	registry.Register("RecordStep", func(ctx core.ExecutionContext, params Params) (core.Executable, error) {
+		step := &recordStep{records: &records}
+		if err := params.Decode(step); err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, step.Replicas)
+		return step, nil
+	})
+
+	definition, err := Parse([]byte(testPipeline))
+	assert.Nil(t, err)
+
+	ctx := core.NewInitExecutionContext(map[string]interface{}{
+		core.SpecKey.Name(): &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "mongo", "namespace": "db"},
+			"spec": map[string]interface{}{
+				"backup":   map[string]interface{}{"mode": "enabled"},
+				"replicas": int64(3),
+				"tls":      false,
+			},
+		}},
+	})
+	step, err := NewLoader(registry).Build(ctx, definition)
+	assert.Nil(t, err)
+	assert.Nil(t, step.Execute(ctx))
+	assert.Equal(t, []string{"deploy mongo to db", "backup"}, records)
+	assert.Equal(t, int64(3), replicas[0])
+
+	t.Run("Unknown step type", func(t *testing.T) {
+		definition, err := Parse([]byte("steps:\n  - type: Unknown\n"))
+		assert.Nil(t, err)
+		_, err = NewLoader(registry).Build(ctx, definition)
+		var unknown *UnknownStepTypeError
+		assert.ErrorAs(t, err, &unknown)
+	})
+
+	t.Run("Unknown parameter", func(t *testing.T) {
+		definition, err := Parse([]byte("steps:\n  - type: RecordStep\n    params:\n      messag: typo\n"))
+		assert.Nil(t, err)
+		_, err = NewLoader(registry).Build(ctx, definition)
+		assert.ErrorContains(t, err, "RecordStep")
+	})
+}
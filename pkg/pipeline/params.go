@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Params are the step parameters with the CR field references resolved
+type Params map[string]interface{}
+
+// Decode converts the parameters to the struct using its json tags
+func (p Params) Decode(target interface{}) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return &core.ExecutionError{Msg: fmt.Sprintf("Failed to decode step parameters: %v", err), Err: err}
+	}
+	return nil
+}
+
+var referenceRegexp = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// specFields returns the CR of the execution context as an unstructured map
+func specFields(ctx core.ExecutionContext) (map[string]interface{}, error) {
+	spec, ok := core.SpecKey.Lookup(ctx)
+	if !ok || spec == nil || reflect.ValueOf(spec).IsNil() {
+		return map[string]interface{}{}, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+}
+
+// lookupField returns the value of the dot separated path, e.g. spec.storage.volumes.0
+func lookupField(fields map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = fields
+	for _, segment := range strings.Split(path, ".") {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			next, ok := value[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			current = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// resolveValue replaces ${path} references in strings of the value.
+// The string which is a single reference is replaced with the referenced value of any type.
+func resolveValue(fields map[string]interface{}, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if match := referenceRegexp.FindStringSubmatch(v); match != nil && match[0] == v {
+			resolved, ok := lookupField(fields, strings.TrimSpace(match[1]))
+			if !ok {
+				return nil, &core.ExecutionError{Msg: fmt.Sprintf("Field %s referenced by the step parameter is not found", match[1])}
+			}
+			return resolved, nil
+		}
+		var resolveErr error
+		result := referenceRegexp.ReplaceAllStringFunc(v, func(reference string) string {
+			path := strings.TrimSpace(reference[2 : len(reference)-1])
+			resolved, ok := lookupField(fields, path)
+			if !ok {
+				resolveErr = &core.ExecutionError{Msg: fmt.Sprintf("Field %s referenced by the step parameter is not found", path)}
+				return reference
+			}
+			return fmt.Sprint(resolved)
+		})
+		return result, resolveErr
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := resolveValue(fields, item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := resolveValue(fields, item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
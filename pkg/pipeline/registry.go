@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+)
+
+// StepFactory creates the step from the resolved parameters.
+// Steps of compounds are built by the loader and added to the returned compound.
+type StepFactory func(ctx core.ExecutionContext, params Params) (core.Executable, error)
+
+type Registry struct {
+	factories map[string]StepFactory
+	mutex     sync.RWMutex
+}
+
+// DefaultRegistry is used by the package level functions, steps of the steps package are registered in it
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns the registry with the core compounds registered
+func NewRegistry() *Registry {
+	registry := &Registry{factories: make(map[string]StepFactory)}
+	registerCompounds(registry)
+	return registry
+}
+
+// Register adds the factory, the factory registered earlier with the same type is replaced
+func (r *Registry) Register(stepType string, factory StepFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.factories[stepType] = factory
+}
+
+func (r *Registry) Lookup(stepType string) (StepFactory, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	factory, ok := r.factories[stepType]
+	return factory, ok
+}
+
+// Types returns the sorted names of the registered step types
+func (r *Registry) Types() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var types []string
+	for stepType := range r.factories {
+		types = append(types, stepType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func Register(stepType string, factory StepFactory) {
+	DefaultRegistry.Register(stepType, factory)
+}
+
+type UnknownStepTypeError struct {
+	Type string
+}
+
+func (e *UnknownStepTypeError) Error() string {
+	return fmt.Sprintf("Step type %s is not registered", e.Type)
+}
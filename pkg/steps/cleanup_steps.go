@@ -0,0 +1,176 @@
+package steps
+
+import (
+	"fmt"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	kubeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeregisterConsulServicesStep removes the service registrations of the CR from Consul
+type DeregisterConsulServicesStep struct {
+	core.DefaultExecutable
+	// Custom casting to AgentServiceRegistration, e.g. if the service ID is generated
+	CastServiceRegistrationFunc ConsulSettingsWrapperCastServiceRegistrationFunction
+	// Retry repeats the deregistration failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
+}
+
+func (r *DeregisterConsulServicesStep) Execute(ctx core.ExecutionContext) error {
+	log := core.LoggerKey.MustGet(ctx)
+	client := core.ConsulKey.MustGet(ctx)
+
+	for name, registration := range core.ConsulServiceRegistrationsKey.Get(ctx) {
+		if registration == nil || !registration.Enabled {
+			continue
+		}
+		serviceRegistration := &registration.AgentServiceRegistration
+		if r.CastServiceRegistrationFunc != nil {
+			serviceRegistration = r.CastServiceRegistrationFunc(ctx, client, registration, log)
+		}
+		log.Info(fmt.Sprintf("Deregistering service %s of %s settings in consul...", serviceRegistration.ID, name))
+		err := retryCall(ctx, r.Retry, "consul deregistration", func() error {
+			return client.Deregister(serviceRegistration.ID)
+		})
+		if err != nil {
+			return &core.ExecutionError{Msg: fmt.Sprintf("Failed to deregister service %s", serviceRegistration.ID), Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *DeregisterConsulServicesStep) Condition(ctx core.ExecutionContext) (bool, error) {
+	if _, ok := core.ConsulKey.Lookup(ctx); !ok {
+		return false, nil
+	}
+	return core.ShouldDelete(ctx, core.ConsulRegistrationsResource), nil
+}
+
+// DeleteConsulProxyServicesStep removes the proxy services of Consul checks created by the registration step
+type DeleteConsulProxyServicesStep struct {
+	core.DefaultExecutable
+	// Retry repeats listing and removal of the services failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
+}
+
+func (r *DeleteConsulProxyServicesStep) Execute(ctx core.ExecutionContext) error {
+	log := core.LoggerKey.MustGet(ctx)
+	kubeCl := core.ClientKey.MustGet(ctx)
+	request := core.RequestKey.MustGet(ctx)
+
+	// Proxy services are labelled with the names of the consul settings, other CRs in the namespace have their own settings
+	var settingsNames []string
+	for name := range core.ConsulServiceRegistrationsKey.Get(ctx) {
+		settingsNames = append(settingsNames, name)
+	}
+	if len(settingsNames) == 0 {
+		return nil
+	}
+	requirement, err := labels.NewRequirement(consulCheckLabelKey, selection.In, settingsNames)
+	if err != nil {
+		return err
+	}
+	servicesList := &v1.ServiceList{}
+	listOps := []kubeClient.ListOption{
+		kubeClient.InNamespace(request.Namespace),
+		kubeClient.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*requirement)},
+	}
+	err = retryCall(ctx, r.Retry, "consul check proxy services listing", func() error {
+		return kubeCl.List(core.GetContext(ctx), servicesList, listOps...)
+	})
+	if err != nil {
+		return &core.ExecutionError{Msg: "Failed listing consul checks proxy services", Err: err}
+	}
+
+	for i := range servicesList.Items {
+		service := &servicesList.Items[i]
+		log.Info("Removing consul check proxy service: " + service.Name)
+		err := retryCall(ctx, r.Retry, "consul check proxy service removal", func() error {
+			return core.DeleteRuntimeObjectContext(core.GetContext(ctx), kubeCl, service)
+		})
+		if err != nil {
+			return &core.ExecutionError{Msg: "Failed removing service: " + service.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *DeleteConsulProxyServicesStep) Condition(ctx core.ExecutionContext) (bool, error) {
+	return core.ShouldDelete(ctx, core.ConsulProxyServicesResource), nil
+}
+
+// DeleteVaultSecretStep removes the secret written to Vault by MoveSecretToVault
+type DeleteVaultSecretStep struct {
+	core.DefaultExecutable
+	SecretName string
+	// Retry repeats the removal failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
+}
+
+func (r *DeleteVaultSecretStep) Execute(ctx core.ExecutionContext) error {
+	log := core.LoggerKey.MustGet(ctx)
+	vaultHelper := core.VaultKey.MustGet(ctx)
+
+	log.Info(fmt.Sprintf("Deleting secret %s in vault", r.SecretName))
+	err := retryCall(ctx, r.Retry, "vault secret removal", func() error {
+		return vaultHelper.DeleteSecret(r.SecretName)
+	})
+	if err != nil {
+		return &core.ExecutionError{Msg: fmt.Sprintf("Failed to delete secret %s in vault", r.SecretName), Err: err}
+	}
+	return nil
+}
+
+func (r *DeleteVaultSecretStep) Condition(ctx core.ExecutionContext) (bool, error) {
+	return core.ShouldDelete(ctx, core.VaultSecretsResource), nil
+}
+
+// DeleteDBEngineStep removes the static role and the database config created by the DB engine step
+type DeleteDBEngineStep struct {
+	core.DefaultExecutable
+	ConfigName string
+	RoleName   string
+	RolePath   string
+	// Retry repeats the Vault calls failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
+}
+
+func NewDeleteDBEngine(configName string, roleName string, rolePath string) *DeleteDBEngineStep {
+	return &DeleteDBEngineStep{
+		ConfigName: configName,
+		RoleName:   roleName,
+		RolePath:   rolePath,
+	}
+}
+
+func (r *DeleteDBEngineStep) Execute(ctx core.ExecutionContext) error {
+	log := core.LoggerKey.MustGet(ctx)
+	v := core.VaultKey.MustGet(ctx)
+
+	if r.RoleName != "" {
+		log.Info(fmt.Sprintf("Deleting static role %s of DB engine", r.RoleName))
+		err := retryCall(ctx, r.Retry, "static role removal", func() error {
+			return v.DeleteStaticRole(r.RolePath + r.RoleName)
+		})
+		if err != nil {
+			return &core.ExecutionError{Msg: fmt.Sprintf("Failed to delete static role %s", r.RoleName), Err: err}
+		}
+	}
+	if r.ConfigName != "" {
+		log.Info(fmt.Sprintf("Deleting DB engine config %s", r.ConfigName))
+		err := retryCall(ctx, r.Retry, "DB engine config removal", func() error {
+			return v.DeleteDatabaseConfig(r.ConfigName)
+		})
+		if err != nil {
+			return &core.ExecutionError{Msg: fmt.Sprintf("Failed to delete DB engine config %s", r.ConfigName), Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *DeleteDBEngineStep) Condition(ctx core.ExecutionContext) (bool, error) {
+	return core.ShouldDelete(ctx, core.VaultDBEnginesResource), nil
+}
@@ -0,0 +1,37 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sTypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDeleteConsulProxyServicesStep(t *testing.T) {
+	newService := func(name string, settingsName string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: name,
+			Labels: map[string]string{consulCheckLabelKey: settingsName}}}
+	}
+	own := newService("own-check", "own")
+	other := newService("other-check", "other")
+	kubeClient := fake.NewClientBuilder().WithObjects(own, other).Build()
+	ctx := core.NewInitExecutionContext(map[string]interface{}{
+		constants.ContextRequest:                    reconcile.Request{NamespacedName: k8sTypes.NamespacedName{Namespace: "namespace", Name: "cr"}},
+		constants.ContextClient:                     kubeClient,
+		constants.ContextLogger:                     core.GetLogger(true),
+		constants.ContextConsulServiceRegistrations: map[string]*types.AgentServiceRegistration{"own": {}},
+	})
+
+	assert.Nil(t, (&DeleteConsulProxyServicesStep{}).Execute(ctx))
+	assert.NotNil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(own), &v1.Service{}))
+	assert.Nil(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(other), &v1.Service{}))
+}
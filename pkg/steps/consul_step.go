@@ -1,7 +1,6 @@
 package steps
 
 import (
-	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -17,9 +16,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime"
 	kubeClient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type ConsulSettingsWrapperExecutionFunction func(ctx core.ExecutionContext, client consul.ConsulClient, srEnabled bool, proxyChecksHosts bool, serviceRegistration *consulApi.AgentServiceRegistration, logger *zap.Logger) error
@@ -44,11 +41,14 @@ type ConsulSettingsWrapperStep struct {
 	AdditionalConditionFunc     ConsulSettingsWrapperAdditionalConditionFunc
 	//
 	SkipHasChangesCheck bool
+	// Retry repeats the Consul and Kubernetes calls of the constructed steps failed with transient errors,
+	// the calls are made once if not set
+	Retry *core.RetryPolicy
 }
 
 func (r *ConsulSettingsWrapperStep) Execute(ctx core.ExecutionContext) error {
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	consulClient := ctx.Get(constants.ContextConsul).(consul.ConsulClient)
+	log := core.LoggerKey.MustGet(ctx)
+	consulClient := core.ConsulKey.MustGet(ctx)
 
 	log.Debug(fmt.Sprintf("Consul step %s is started", r.Name))
 	defer log.Debug(fmt.Sprintf("Consul step %s is ended", r.Name))
@@ -63,17 +63,15 @@ func (r *ConsulSettingsWrapperStep) Execute(ctx core.ExecutionContext) error {
 }
 
 func (r *ConsulSettingsWrapperStep) Condition(ctx core.ExecutionContext) (bool, error) {
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	log := core.LoggerKey.MustGet(ctx)
 
-	if ctx.Get(constants.ContextConsulRegistration) == nil ||
-		ctx.Get(constants.ContextConsul) == nil {
+	consulRegistration, registrationOk := core.ConsulRegistrationKey.Lookup(ctx)
+	consulClient, clientOk := core.ConsulKey.Lookup(ctx)
+	if !registrationOk || !clientOk {
 		log.Debug("Consul client is not set. Skipping step...")
 		return false, nil
 	}
 
-	consulRegistration := ctx.Get(constants.ContextConsulRegistration).(*types.ConsulRegistration)
-	consulClient := ctx.Get(constants.ContextConsul).(consul.ConsulClient)
-
 	if len(r.ConsulSettingsName) != 0 {
 		sr := FindServiceRegistrationByKey(ctx, r.ConsulSettingsName)
 		if sr == nil {
@@ -135,15 +133,16 @@ func (r *ConsulSettingsWrapperStep) Condition(ctx core.ExecutionContext) (bool,
 func NewRegisterConsulServiceStep(
 	settingsName string,
 	castFunc ConsulSettingsWrapperCastServiceRegistrationFunction) *ConsulSettingsWrapperStep {
-	return &ConsulSettingsWrapperStep{
+	var step *ConsulSettingsWrapperStep
+	step = &ConsulSettingsWrapperStep{
 		Name:                        fmt.Sprintf("%s registration/deregistration", settingsName),
 		ConsulSettingsName:          settingsName,
 		CastServiceRegistrationFunc: castFunc,
 		ExecuteFunc: func(ctx core.ExecutionContext, client consul.ConsulClient, srEnabled bool, proxyChecksHosts bool, serviceRegistration *consulApi.AgentServiceRegistration, logger *zap.Logger) error {
-			kubeCl := ctx.Get(constants.ContextClient).(kubeClient.Client)
-			request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-			scheme := ctx.Get(constants.ContextSchema).(*runtime.Scheme)
-			helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
+			kubeCl := core.ClientKey.MustGet(ctx)
+			request := core.RequestKey.MustGet(ctx)
+			scheme := core.SchemaKey.MustGet(ctx)
+			helperImpl := core.GetKubernetesHelper(ctx)
 
 			serviceLabels := map[string]string{consulCheckLabelKey: settingsName}
 
@@ -157,7 +156,10 @@ func NewRegisterConsulServiceStep(
 				},
 			}
 
-			if err := kubeCl.List(context.Background(), servicesList, listOps...); err != nil {
+			err := retryCall(ctx, step.Retry, "consul check proxy services listing", func() error {
+				return kubeCl.List(core.GetContext(ctx), servicesList, listOps...)
+			})
+			if err != nil {
 				if errors.IsNotFound(err) {
 					logger.Debug("Services to delete not found")
 				} else {
@@ -167,7 +169,9 @@ func NewRegisterConsulServiceStep(
 				for _, service := range servicesList.Items {
 					logger.Debug("Removing service: " + service.Name)
 					core.HandleError(
-						core.DeleteRuntimeObject(kubeCl, &service),
+						retryCall(ctx, step.Retry, "consul check proxy service removal", func() error {
+							return core.DeleteRuntimeObjectContext(core.GetContext(ctx), kubeCl, &service)
+						}),
 						logger.Error,
 						"Failed removing service: "+service.Name)
 				}
@@ -175,7 +179,9 @@ func NewRegisterConsulServiceStep(
 
 			if !srEnabled {
 				logger.Debug(fmt.Sprintf("Performing service %s deregistration in consul...", serviceRegistration.ID))
-				deregErr := client.Deregister(serviceRegistration.ID)
+				deregErr := retryCall(ctx, step.Retry, "consul deregistration", func() error {
+					return client.Deregister(serviceRegistration.ID)
+				})
 				core.HandleError(deregErr, logger.Warn, "Failed during service deregistration")
 				return nil
 			} else {
@@ -198,7 +204,9 @@ func NewRegisterConsulServiceStep(
 
 						// create service
 						core.PanicError(
-							helperImpl.CreateRuntimeObject(scheme, nil, service, service.ObjectMeta),
+							retryCall(ctx, step.Retry, "consul check proxy service creation", func() error {
+								return helperImpl.CreateRuntimeObject(scheme, nil, service, service.ObjectMeta)
+							}),
 							logger.Error,
 							"Failed creating proxy service for consul check")
 
@@ -212,10 +220,13 @@ func NewRegisterConsulServiceStep(
 					}
 				}
 
-				return client.Register(serviceRegistration)
+				return retryCall(ctx, step.Retry, "consul registration", func() error {
+					return client.Register(serviceRegistration)
+				})
 			}
 		},
 	}
+	return step
 }
 
 func NewMaintenanceConsulServiceStep(
@@ -223,7 +234,8 @@ func NewMaintenanceConsulServiceStep(
 	castFunc ConsulSettingsWrapperCastServiceRegistrationFunction,
 	isMainteaning bool,
 	reason ...string) *ConsulSettingsWrapperStep {
-	return &ConsulSettingsWrapperStep{
+	var step *ConsulSettingsWrapperStep
+	step = &ConsulSettingsWrapperStep{
 		Name:                        fmt.Sprintf("%s maintenance", settingsName),
 		ConsulSettingsName:          settingsName,
 		CastServiceRegistrationFunc: castFunc,
@@ -236,9 +248,12 @@ func NewMaintenanceConsulServiceStep(
 			return true, nil
 		},
 		ExecuteFunc: func(ctx core.ExecutionContext, client consul.ConsulClient, srEnabled bool, proxyChecksHosts bool, serviceRegistration *consulApi.AgentServiceRegistration, logger *zap.Logger) error {
-			return client.Maintenance(serviceRegistration.ID, isMainteaning, reason...)
+			return retryCall(ctx, step.Retry, "consul maintenance", func() error {
+				return client.Maintenance(serviceRegistration.ID, isMainteaning, reason...)
+			})
 		},
 	}
+	return step
 }
 
 func (r *ConsulSettingsWrapperStep) CastAndUpdateConsulServiceRegistration(ctx core.ExecutionContext, client consul.ConsulClient, registration *types.AgentServiceRegistration, logger *zap.Logger) *consulApi.AgentServiceRegistration {
@@ -250,6 +265,6 @@ func (r *ConsulSettingsWrapperStep) CastAndUpdateConsulServiceRegistration(ctx c
 }
 
 func FindServiceRegistrationByKey(ctx core.ExecutionContext, key string) *types.AgentServiceRegistration {
-	regs := ctx.Get(constants.ContextConsulServiceRegistrations).(map[string]*types.AgentServiceRegistration)
+	regs := core.ConsulServiceRegistrationsKey.MustGet(ctx)
 	return regs[key]
 }
@@ -4,12 +4,8 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	mTypes "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/vault"
-	"go.uber.org/zap"
-	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 type createDBEngine struct {
@@ -19,8 +15,13 @@ type createDBEngine struct {
 	ConfigSettings map[string]interface{}
 	RoleSettings   map[string]interface{}
 	RolePath       string
-	WaitTimeout    int
-	ConditionFunc  func() (bool, error)
+	// WaitTimeout limits in seconds the attempts of the DB engine configuration if Retry is not set, 120 if not set
+	WaitTimeout   int
+	ConditionFunc func() (bool, error)
+	// Retry is applied to the DB engine configuration and the static role creation.
+	// If not set, the configuration is attempted every 5 seconds within WaitTimeout as the database may be not ready yet,
+	// the role creation is made once.
+	Retry *core.RetryPolicy
 }
 
 func NewCreateDBEngine(configName string, configSettings map[string]interface{}, roleName string, rolePath string, roleSettings map[string]interface{}) *createDBEngine {
@@ -33,27 +34,39 @@ func NewCreateDBEngine(configName string, configSettings map[string]interface{},
 	}
 }
 
+// configRetryPolicy returns Retry if set, otherwise the configuration is attempted every 5 seconds within WaitTimeout
+func (r *createDBEngine) configRetryPolicy() core.RetryPolicy {
+	if r.Retry != nil {
+		return *r.Retry
+	}
+	waitTimeout := r.WaitTimeout
+	if waitTimeout <= 0 {
+		waitTimeout = 120
+	}
+	interval := 5 * time.Second
+	return core.RetryPolicy{
+		Attempts:  int(time.Duration(waitTimeout)*time.Second/interval) + 1,
+		Backoff:   interval,
+		Retryable: core.RetryAll,
+	}
+}
+
 func (r *createDBEngine) Execute(ctx core.ExecutionContext) error {
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	v := ctx.Get(constants.ContextVault).(vault.VaultHelper)
+	log := core.LoggerKey.MustGet(ctx)
+	v := core.VaultKey.MustGet(ctx)
 	//in case if some of variables are lazy
 	parseSettings(r.ConfigSettings)
 
-	var err error
-	dbError := wait.PollImmediate(5*time.Second, time.Second*time.Duration(120), func() (bool, error) {
+	dbError := r.configRetryPolicy().DoWithContext(core.GetContext(ctx), log, "DB engine configuration", func() error {
 		log.Debug(fmt.Sprintf("Trying to configurate Database %s", r.ConfigName))
-		vaultErr := v.CreateDatabaseConfig(r.ConfigName, r.ConfigSettings)
-		if vaultErr != nil {
-			log.Debug(fmt.Sprintf("Failed to configurate DB engine with err %v", vaultErr))
-			err = vaultErr
-		}
-
-		return vaultErr == nil, nil
+		return v.CreateDatabaseConfig(r.ConfigName, r.ConfigSettings)
 	})
-	core.PanicError(dbError, log.Error, fmt.Sprintf("All attempts to configurate DB engine %s failed. Response error is %v", r.ConfigName, err))
+	core.PanicError(dbError, log.Error, fmt.Sprintf("All attempts to configurate DB engine %s failed", r.ConfigName))
 
 	parseSettings(r.RoleSettings)
-	err = v.CreateStaticRole(r.RolePath+r.RoleName, r.RoleSettings)
+	err := retryCall(ctx, r.Retry, "static role creation", func() error {
+		return v.CreateStaticRole(r.RolePath+r.RoleName, r.RoleSettings)
+	})
 	core.PanicError(err, log.Error, fmt.Sprintf("Could not create role for DB engine %s", r.RoleName))
 	return nil
 }
@@ -71,20 +84,38 @@ type SetPasswordFromVaultRole struct {
 	Registration          mTypes.VaultRegistration
 	RoleName              string
 	CtxVarToStorePassword string
+	// Retry repeats reading of the role failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
 }
 
 func (r *SetPasswordFromVaultRole) Execute(ctx core.ExecutionContext) error {
-	vaultHelper := ctx.Get(constants.ContextVault).(vault.VaultHelper)
-	roleMap, err := vaultHelper.GetStaticRoleCredentials(r.RoleName)
+	vaultHelper := core.VaultKey.MustGet(ctx)
+	var roleMap map[string]interface{}
+	err := retryCall(ctx, r.Retry, "static role credentials reading", func() (callErr error) {
+		roleMap, callErr = vaultHelper.GetStaticRoleCredentials(r.RoleName)
+		return
+	})
 	ctx.Set(r.CtxVarToStorePassword, roleMap["password"])
 	return err
 }
 
 func (r *SetPasswordFromVaultRole) Condition(ctx core.ExecutionContext) (bool, error) {
-	vaultHelper := ctx.Get(constants.ContextVault).(vault.VaultHelper)
-	exists, err := vaultHelper.IsStaticRoleExists(r.RoleName)
+	vaultHelper := core.VaultKey.MustGet(ctx)
+	var exists bool
+	err := retryCall(ctx, r.Retry, "static role check", func() (callErr error) {
+		exists, callErr = vaultHelper.IsStaticRoleExists(r.RoleName)
+		return
+	})
 	if err != nil {
 		return false, nil
 	}
 	return exists, nil
 }
+
+func (r *SetPasswordFromVaultRole) Requires() []string {
+	return nil
+}
+
+func (r *SetPasswordFromVaultRole) Produces() []string {
+	return []string{r.CtxVarToStorePassword}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	mTypes "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/vault"
-	"go.uber.org/zap"
 )
 
 type MoveSecretToVault struct {
@@ -19,21 +18,28 @@ type MoveSecretToVault struct {
 	VaultRegistration     *mTypes.VaultRegistration
 	CtxVarToStorePassword string
 	ConditionFunc         func() (bool, error)
+	// Retry repeats the Vault calls failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
 }
 
 func (r *MoveSecretToVault) Execute(ctx core.ExecutionContext) error {
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	vaultHelper := ctx.Get(constants.ContextVault).(vault.VaultHelper)
+	log := core.LoggerKey.MustGet(ctx)
+	vaultHelper := core.VaultKey.MustGet(ctx)
 	log.Info("vault.MoveSecretToVault step is started")
 
 	var err error
 
 	if r.Password == "" {
-		r.Password, err = vaultHelper.GeneratePassword(r.Policy)
+		err = retryCall(ctx, r.Retry, "password generation", func() (callErr error) {
+			r.Password, callErr = vaultHelper.GeneratePassword(r.Policy)
+			return
+		})
 	}
 	core.PanicError(err, log.Error, fmt.Sprintf("Failed to generate password for secret %s", r.SecretName))
 
-	err = vaultHelper.StorePassword(r.SecretName, r.Password)
+	err = retryCall(ctx, r.Retry, "password storing", func() error {
+		return vaultHelper.StorePassword(r.SecretName, r.Password)
+	})
 	core.PanicError(err, log.Error, fmt.Sprintf("Failed to store password for secret %s", r.SecretName))
 
 	if r.CtxVarToStorePassword != "" {
@@ -47,10 +53,15 @@ func (r *MoveSecretToVault) Condition(ctx core.ExecutionContext) (bool, error) {
 	if r.ConditionFunc != nil {
 		return r.ConditionFunc()
 	}
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
-	v := ctx.Get(constants.ContextVault).(vault.VaultHelper)
+	log := core.LoggerKey.MustGet(ctx)
+	v := core.VaultKey.MustGet(ctx)
 
-	passwordExists, password, secretExistsErr := checkPasswordExists(v, r.SecretName)
+	var passwordExists bool
+	var password string
+	secretExistsErr := retryCall(ctx, r.Retry, "secret check", func() (callErr error) {
+		passwordExists, password, callErr = checkPasswordExists(v, r.SecretName)
+		return
+	})
 	core.PanicError(secretExistsErr, log.Error, fmt.Sprintf("Reading secret %s in vault failed", r.SecretName))
 
 	if passwordExists {
@@ -63,6 +74,17 @@ func (r *MoveSecretToVault) Condition(ctx core.ExecutionContext) (bool, error) {
 	return !passwordExists, nil
 }
 
+func (r *MoveSecretToVault) Requires() []string {
+	return nil
+}
+
+func (r *MoveSecretToVault) Produces() []string {
+	if r.CtxVarToStorePassword == "" {
+		return nil
+	}
+	return []string{r.CtxVarToStorePassword}
+}
+
 func checkPasswordExists(vaultHelper vault.VaultHelper, secretName string) (bool, string, error) {
 	secretExists, secret, err := vaultHelper.CheckSecretExists(secretName)
 
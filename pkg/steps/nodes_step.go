@@ -1,23 +1,21 @@
 package steps
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
-	"go.uber.org/zap"
 	v1core "k8s.io/api/core/v1"
 	kTypes "k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type StoreNodesStep struct {
 	core.Executable
 	Storage           *types.StorageRequirements
 	ContextVarToStore string
+	// Retry repeats reading of the PVs failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
 }
 
 func (r *StoreNodesStep) Validate(ctx core.ExecutionContext) error {
@@ -26,7 +24,7 @@ func (r *StoreNodesStep) Validate(ctx core.ExecutionContext) error {
 
 func (r *StoreNodesStep) Execute(ctx core.ExecutionContext) error {
 
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	log := core.LoggerKey.MustGet(ctx)
 	log.Info("Store Nodes step is started")
 	// Are nodes set by request?
 	storage := &r.Storage
@@ -35,8 +33,8 @@ func (r *StoreNodesStep) Execute(ctx core.ExecutionContext) error {
 
 		//log.Warn("Nodes are not specified. Trying to get it by volumes may cause RBAC errors...")
 
-		client := ctx.Get(constants.ContextClient).(client.Client)
-		request := ctx.Get(constants.ContextRequest).(reconcile.Request)
+		client := core.ClientKey.MustGet(ctx)
+		request := core.RequestKey.MustGet(ctx)
 
 		volumeNames := []string{}
 
@@ -72,9 +70,11 @@ func (r *StoreNodesStep) Execute(ctx core.ExecutionContext) error {
 			log.Debug("Trying to get node from " + pvName + " PV")
 			pv := &v1core.PersistentVolume{}
 
-			err := client.Get(context.Background(), kTypes.NamespacedName{
-				Name: pvName, Namespace: request.Namespace,
-			}, pv)
+			err := retryCall(ctx, r.Retry, "PV reading", func() error {
+				return client.Get(core.GetContext(ctx), kTypes.NamespacedName{
+					Name: pvName, Namespace: request.Namespace,
+				}, pv)
+			})
 
 			//Try to get pv. If error - restricted env
 			if err != nil {
@@ -111,3 +111,11 @@ func (r *StoreNodesStep) Execute(ctx core.ExecutionContext) error {
 func (r *StoreNodesStep) Condition(ctx core.ExecutionContext) (bool, error) {
 	return true, nil
 }
+
+func (r *StoreNodesStep) Requires() []string {
+	return nil
+}
+
+func (r *StoreNodesStep) Produces() []string {
+	return []string{r.ContextVarToStore}
+}
@@ -3,14 +3,12 @@ package steps
 import (
 	"fmt"
 
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
-	"go.uber.org/zap"
 	v1core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -26,29 +24,42 @@ type CreatePVCStep struct {
 	Owner             v1.Object
 	WaitPVCBound      bool
 	AccessMode        v1core.PersistentVolumeAccessMode
+	// StoragePath is the path of Storage in the CR used in validation errors, e.g. spec.storage
+	StoragePath string
+	// Retry repeats reading and creation of the PVCs failed with transient errors, the calls are made once if not set
+	Retry       *core.RetryPolicy
+	createdPVCs []string
 }
 
 func (r *CreatePVCStep) Validate(ctx core.ExecutionContext) error {
 	storage := r.Storage
-
-	if storage == nil ||
-		len((*storage).Size) == 0 ||
-		((*storage).MatchLabelSelectors == nil &&
-			(*storage).Volumes == nil &&
-			(*storage).StorageClasses == nil) {
-		return &core.ExecutionError{Msg: "Storage size should be set with volumes or storage classes or label selectors"}
+	storagePath := r.StoragePath
+	if storagePath == "" {
+		storagePath = "storage"
 	}
 
-	return nil
+	if storage == nil {
+		return core.NewFieldError(storagePath, "Storage should be set")
+	}
+	validationErr := &core.ValidationError{}
+	if len(storage.Size) == 0 {
+		validationErr.Add("", core.NewFieldError(storagePath+".size", "Storage size should be set"))
+	}
+	if storage.MatchLabelSelectors == nil && storage.Volumes == nil && storage.StorageClasses == nil {
+		validationErr.Add("", core.NewFieldError(storagePath, "Volumes or storage classes or label selectors should be set"))
+	}
+	return validationErr.ErrorOrNil()
 }
 
 func (r *CreatePVCStep) Execute(ctx core.ExecutionContext) error {
-	var request reconcile.Request = ctx.Get(constants.ContextRequest).(reconcile.Request)
-	scheme := ctx.Get(constants.ContextSchema).(*runtime.Scheme)
-	helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	var request reconcile.Request = core.RequestKey.MustGet(ctx)
+	scheme := core.SchemaKey.MustGet(ctx)
+	helperImpl := core.GetKubernetesHelper(ctx)
+	kubeClient := core.ClientKey.MustGet(ctx)
+	log := core.LoggerKey.MustGet(ctx)
 
 	log.Info("PVC Creation/Checking step is started")
+	r.createdPVCs = nil
 	maxSize := r.PVCCount(ctx)
 	log.Debug(fmt.Sprintf("PVC count is: %v", maxSize))
 
@@ -59,11 +70,25 @@ func (r *CreatePVCStep) Execute(ctx core.ExecutionContext) error {
 	var pvcArray []string
 	for i := r.StartIndex; i < (maxSize + r.StartIndex); i++ {
 		template := utils.PVCTemplate(*r.Storage, i, r.NameFormat, r.LabelSelector, request.Namespace, r.AccessMode)
+		// only PVCs which are known to be absent are removed on rollback, they may keep the data otherwise
+		getErr := retryCall(ctx, r.Retry, "PVC reading", func() error {
+			return core.ListRuntimeObjectsByNameContext(core.GetContext(ctx), &v1core.PersistentVolumeClaim{}, kubeClient, request.Namespace, template.ObjectMeta.Name)
+		})
+		if getErr != nil && !errors.IsNotFound(getErr) {
+			core.PanicError(getErr, log.Error, "Checking of PVC "+template.ObjectMeta.Name+" failed")
+		}
+		existed := getErr == nil
 
-		err := helperImpl.CreateRuntimeObject(scheme, r.Owner, template, template.ObjectMeta)
+		err := retryCall(ctx, r.Retry, "PVC creation", func() error {
+			return helperImpl.CreateRuntimeObject(scheme, r.Owner, template, template.ObjectMeta)
+		})
 
 		core.PanicError(err, log.Error, "Creating of PVC "+template.ObjectMeta.Name+" failed")
 
+		if !existed {
+			r.createdPVCs = append(r.createdPVCs, template.ObjectMeta.Name)
+		}
+
 		pvcArray = append(pvcArray, template.ObjectMeta.Name)
 	}
 
@@ -77,13 +102,41 @@ func (r *CreatePVCStep) Execute(ctx core.ExecutionContext) error {
 		}
 	}
 
-	if fromCtx, ok := ctx.Get(r.ContextVarToStore).([]string); ok {
+	pvcNamesKey := core.NewKey[[]string](r.ContextVarToStore)
+	if fromCtx, ok := pvcNamesKey.Lookup(ctx); ok {
 		pvcArray = append(fromCtx, pvcArray...)
 	}
-	ctx.Set(r.ContextVarToStore, pvcArray)
+	pvcNamesKey.Set(ctx, pvcArray)
 	return nil
 }
 
 func (r *CreatePVCStep) Condition(ctx core.ExecutionContext) (bool, error) {
 	return true, nil
 }
+
+// Rollback removes PVCs which did not exist before the last execution of the step
+func (r *CreatePVCStep) Rollback(ctx core.ExecutionContext) error {
+	request := core.RequestKey.MustGet(ctx)
+	kubeClient := core.ClientKey.MustGet(ctx)
+	log := core.LoggerKey.MustGet(ctx)
+
+	for _, pvcName := range r.createdPVCs {
+		log.Info(fmt.Sprintf("Removing PVC %s created by the failed reconcile", pvcName))
+		err := core.DeleteRuntimeObjectContext(core.GetContext(ctx), kubeClient, &v1core.PersistentVolumeClaim{
+			ObjectMeta: v1.ObjectMeta{Name: pvcName, Namespace: request.Namespace},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.createdPVCs = nil
+	return nil
+}
+
+func (r *CreatePVCStep) Requires() []string {
+	return nil
+}
+
+func (r *CreatePVCStep) Produces() []string {
+	return []string{r.ContextVarToStore}
+}
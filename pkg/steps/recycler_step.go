@@ -6,13 +6,9 @@ import (
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
-	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	v12 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type PVRecyclerStep struct {
@@ -27,17 +23,19 @@ type PVRecyclerStep struct {
 	Resources          *corev1.ResourceRequirements
 	Owner              metav1.Object
 	ConditionFunc      func(ctx core.ExecutionContext) (bool, error)
+	// Retry repeats creation of the recycler pods failed with transient errors, the calls are made once if not set
+	Retry *core.RetryPolicy
 }
 
 func (r *PVRecyclerStep) Execute(ctx core.ExecutionContext) error {
-	client := ctx.Get(constants.ContextClient).(client.Client)
-	request := ctx.Get(constants.ContextRequest).(reconcile.Request)
-	scheme := ctx.Get(constants.ContextSchema).(*runtime.Scheme)
-	helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
-	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+	client := core.ClientKey.MustGet(ctx)
+	request := core.RequestKey.MustGet(ctx)
+	scheme := core.SchemaKey.MustGet(ctx)
+	helperImpl := core.GetKubernetesHelper(ctx)
+	log := core.LoggerKey.MustGet(ctx)
 
-	pvcNames := ctx.Get(r.PVCContextVar).([]string)
-	nodeLabels := ctx.Get(r.PVNodesContextVar).([]map[string]string)
+	pvcNames := core.NewKey[[]string](r.PVCContextVar).MustGet(ctx)
+	nodeLabels := core.NewKey[[]map[string]string](r.PVNodesContextVar).MustGet(ctx)
 
 	pvcSize := len(pvcNames)
 
@@ -56,13 +54,15 @@ func (r *PVRecyclerStep) Execute(ctx core.ExecutionContext) error {
 
 			recyclerPodNames = append(recyclerPodNames, recyclerPodTemplate.Name)
 
-			err := helperImpl.CreateRuntimeObject(scheme, r.Owner, recyclerPodTemplate, recyclerPodTemplate.ObjectMeta)
+			err := retryCall(ctx, r.Retry, "recycler pod creation", func() error {
+				return helperImpl.CreateRuntimeObject(scheme, r.Owner, recyclerPodTemplate, recyclerPodTemplate.ObjectMeta)
+			})
 			core.PanicError(err, log.Error, "Recycler pod creation failed")
 
 			log.Debug(fmt.Sprintf("Recycler pod %s is created", recyclerPodTemplate.Name))
 		}
 
-		helperImpl := ctx.Get(constants.KubernetesHelperImpl).(core.KubernetesHelper)
+		helperImpl := core.GetKubernetesHelper(ctx)
 		err := helperImpl.WaitForPodsCompleted(
 			map[string]string{
 				constants.Microservice: constants.RecyclerPod,
@@ -81,7 +81,8 @@ func (r *PVRecyclerStep) Execute(ctx core.ExecutionContext) error {
 		}
 
 		for _, name := range recyclerPodNames {
-			err = core.DeleteRuntimeObject(
+			err = core.DeleteRuntimeObjectContext(
+				core.GetContext(ctx),
 				client,
 				&v12.Pod{
 					ObjectMeta: metav1.ObjectMeta{
@@ -116,3 +117,11 @@ func (r *PVRecyclerStep) Condition(ctx core.ExecutionContext) (bool, error) {
 	}
 	return core.GetCurrentDeployType(ctx) == core.CleanDeploy, nil
 }
+
+func (r *PVRecyclerStep) Requires() []string {
+	return []string{r.PVCContextVar, r.PVNodesContextVar}
+}
+
+func (r *PVRecyclerStep) Produces() []string {
+	return nil
+}
@@ -0,0 +1,223 @@
+package steps
+
+import (
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/pipeline"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Step types of the declarative pipeline
+const (
+	CreatePVCStepType                 = "CreatePVCStep"
+	StoreNodesStepType                = "StoreNodesStep"
+	PVRecyclerStepType                = "PVRecyclerStep"
+	MoveSecretToVaultType             = "MoveSecretToVault"
+	CreateDBEngineType                = "CreateDBEngine"
+	SetPasswordFromVaultRoleType      = "SetPasswordFromVaultRole"
+	RegisterConsulServiceStepType     = "RegisterConsulServiceStep"
+	MaintenanceConsulServiceStepType  = "MaintenanceConsulServiceStep"
+	DeregisterConsulServicesStepType  = "DeregisterConsulServicesStep"
+	DeleteConsulProxyServicesStepType = "DeleteConsulProxyServicesStep"
+	DeleteVaultSecretStepType         = "DeleteVaultSecretStep"
+	DeleteDBEngineStepType            = "DeleteDBEngineStep"
+)
+
+func init() {
+	RegisterSteps(pipeline.DefaultRegistry)
+}
+
+type createPVCParams struct {
+	Storage           *types.StorageRequirements        `json:"storage"`
+	NameFormat        string                            `json:"nameFormat"`
+	LabelSelector     map[string]string                 `json:"labelSelector,omitempty"`
+	ContextVarToStore string                            `json:"contextVarToStore"`
+	WaitTimeout       int                               `json:"waitTimeout,omitempty"`
+	Count             int                               `json:"count,omitempty"`
+	StartIndex        int                               `json:"startIndex,omitempty"`
+	WaitPVCBound      bool                              `json:"waitPvcBound,omitempty"`
+	AccessMode        corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+	StoragePath       string                            `json:"storagePath,omitempty"`
+}
+
+type storeNodesParams struct {
+	Storage           *types.StorageRequirements `json:"storage"`
+	ContextVarToStore string                     `json:"contextVarToStore"`
+}
+
+type pvRecyclerParams struct {
+	DockerImage        string                       `json:"dockerImage"`
+	Volumes            []string                     `json:"volumes,omitempty"`
+	Tolerations        []corev1.Toleration          `json:"tolerations,omitempty"`
+	PVCContextVar      string                       `json:"pvcContextVar"`
+	PVNodesContextVar  string                       `json:"pvNodesContextVar"`
+	WaitTimeout        int                          `json:"waitTimeout,omitempty"`
+	PodSecurityContext *corev1.PodSecurityContext   `json:"podSecurityContext,omitempty"`
+	Resources          *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+type moveSecretToVaultParams struct {
+	Password              string                   `json:"password,omitempty"`
+	SecretName            string                   `json:"secretName"`
+	PolicyName            string                   `json:"policyName,omitempty"`
+	Policy                string                   `json:"policy,omitempty"`
+	VaultRegistration     *types.VaultRegistration `json:"vaultRegistration,omitempty"`
+	CtxVarToStorePassword string                   `json:"ctxVarToStorePassword,omitempty"`
+}
+
+type createDBEngineParams struct {
+	ConfigName     string                 `json:"configName"`
+	ConfigSettings map[string]interface{} `json:"configSettings,omitempty"`
+	RoleName       string                 `json:"roleName"`
+	RolePath       string                 `json:"rolePath,omitempty"`
+	RoleSettings   map[string]interface{} `json:"roleSettings,omitempty"`
+	WaitTimeout    int                    `json:"waitTimeout,omitempty"`
+}
+
+type setPasswordFromVaultRoleParams struct {
+	RoleName              string `json:"roleName"`
+	CtxVarToStorePassword string `json:"ctxVarToStorePassword"`
+}
+
+type consulServiceParams struct {
+	SettingsName  string   `json:"settingsName"`
+	IsMaintenance bool     `json:"isMaintenance,omitempty"`
+	Reason        []string `json:"reason,omitempty"`
+}
+
+type deleteVaultSecretParams struct {
+	SecretName string `json:"secretName"`
+}
+
+type deleteDBEngineParams struct {
+	ConfigName string `json:"configName,omitempty"`
+	RoleName   string `json:"roleName,omitempty"`
+	RolePath   string `json:"rolePath,omitempty"`
+}
+
+// RegisterSteps adds the steps of the package to the pipeline registry, they are registered in the default one on init
+func RegisterSteps(registry *pipeline.Registry) {
+	registry.Register(CreatePVCStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := createPVCParams{Count: 1}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		count := p.Count
+		return &CreatePVCStep{
+			Storage:           p.Storage,
+			NameFormat:        p.NameFormat,
+			LabelSelector:     p.LabelSelector,
+			ContextVarToStore: p.ContextVarToStore,
+			WaitTimeout:       p.WaitTimeout,
+			PVCCount:          func(ctx core.ExecutionContext) int { return count },
+			StartIndex:        p.StartIndex,
+			Owner:             specOwner(ctx),
+			WaitPVCBound:      p.WaitPVCBound,
+			AccessMode:        p.AccessMode,
+			StoragePath:       p.StoragePath,
+		}, nil
+	})
+	registry.Register(StoreNodesStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := storeNodesParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return &StoreNodesStep{Storage: p.Storage, ContextVarToStore: p.ContextVarToStore}, nil
+	})
+	registry.Register(PVRecyclerStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := pvRecyclerParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return &PVRecyclerStep{
+			DockerImage:        p.DockerImage,
+			Volumes:            p.Volumes,
+			Tolerations:        p.Tolerations,
+			PVCContextVar:      p.PVCContextVar,
+			PVNodesContextVar:  p.PVNodesContextVar,
+			WaitTimeout:        p.WaitTimeout,
+			PodSecurityContext: p.PodSecurityContext,
+			Resources:          p.Resources,
+			Owner:              specOwner(ctx),
+		}, nil
+	})
+	registry.Register(MoveSecretToVaultType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := moveSecretToVaultParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return &MoveSecretToVault{
+			Password:              p.Password,
+			SecretName:            p.SecretName,
+			PolicyName:            p.PolicyName,
+			Policy:                p.Policy,
+			VaultRegistration:     p.VaultRegistration,
+			CtxVarToStorePassword: p.CtxVarToStorePassword,
+		}, nil
+	})
+	registry.Register(CreateDBEngineType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := createDBEngineParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		step := NewCreateDBEngine(p.ConfigName, p.ConfigSettings, p.RoleName, p.RolePath, p.RoleSettings)
+		step.WaitTimeout = p.WaitTimeout
+		return step, nil
+	})
+	registry.Register(SetPasswordFromVaultRoleType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := setPasswordFromVaultRoleParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return &SetPasswordFromVaultRole{RoleName: p.RoleName, CtxVarToStorePassword: p.CtxVarToStorePassword}, nil
+	})
+	registry.Register(RegisterConsulServiceStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := consulServiceParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return NewRegisterConsulServiceStep(p.SettingsName, nil), nil
+	})
+	registry.Register(MaintenanceConsulServiceStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := consulServiceParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return NewMaintenanceConsulServiceStep(p.SettingsName, nil, p.IsMaintenance, p.Reason...), nil
+	})
+	registry.Register(DeregisterConsulServicesStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		if err := params.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return &DeregisterConsulServicesStep{}, nil
+	})
+	registry.Register(DeleteConsulProxyServicesStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		if err := params.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return &DeleteConsulProxyServicesStep{}, nil
+	})
+	registry.Register(DeleteVaultSecretStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := deleteVaultSecretParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return &DeleteVaultSecretStep{SecretName: p.SecretName}, nil
+	})
+	registry.Register(DeleteDBEngineStepType, func(ctx core.ExecutionContext, params pipeline.Params) (core.Executable, error) {
+		p := deleteDBEngineParams{}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return NewDeleteDBEngine(p.ConfigName, p.RoleName, p.RolePath), nil
+	})
+}
+
+// specOwner returns the CR of the execution context as the owner of the created objects
+func specOwner(ctx core.ExecutionContext) metav1.Object {
+	if spec, ok := core.SpecKey.Lookup(ctx); ok && spec != nil {
+		return spec
+	}
+	return nil
+}
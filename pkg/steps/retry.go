@@ -0,0 +1,15 @@
+package steps
+
+import (
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+)
+
+// retryCall repeats the Kubernetes, Vault or Consul call according to the policy of the step.
+// The retries are opt-in, the call is made once if the policy is not set.
+func retryCall(ctx core.ExecutionContext, policy *core.RetryPolicy, name string, call func() error) error {
+	var retry core.RetryPolicy
+	if policy != nil {
+		retry = *policy
+	}
+	return retry.DoWithContext(core.GetContext(ctx), core.LoggerKey.Get(ctx), name, call)
+}
@@ -0,0 +1,52 @@
+package steps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/vault/mocks"
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepRetries(t *testing.T) {
+	policy := &core.RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	newContext := func(vaultHelper *mocks.FakeVaultHelper) core.ExecutionContext {
+		return core.NewInitExecutionContext(map[string]interface{}{
+			constants.ContextVault:  vaultHelper,
+			constants.ContextLogger: core.GetLogger(true),
+		})
+	}
+
+	t.Run("Transient failure is retried", func(t *testing.T) {
+		vaultHelper := &mocks.FakeVaultHelper{}
+		vaultHelper.On("StorePassword", "secret", "password").Return(&api.ResponseError{StatusCode: 503}).Once()
+		vaultHelper.On("StorePassword", "secret", "password").Return(nil).Once()
+
+		step := &MoveSecretToVault{SecretName: "secret", Password: "password", CtxVarToStorePassword: "password", Retry: policy}
+		ctx := newContext(vaultHelper)
+		assert.Nil(t, step.Execute(ctx))
+		assert.Equal(t, "password", ctx.Get("password"))
+		vaultHelper.AssertNumberOfCalls(t, "StorePassword", 2)
+	})
+
+	t.Run("Retries are opt-in", func(t *testing.T) {
+		vaultHelper := &mocks.FakeVaultHelper{}
+		vaultHelper.On("DeleteSecret", "secret").Return(&api.ResponseError{StatusCode: 503})
+
+		step := &DeleteVaultSecretStep{SecretName: "secret"}
+		assert.NotNil(t, step.Execute(newContext(vaultHelper)))
+		vaultHelper.AssertNumberOfCalls(t, "DeleteSecret", 1)
+	})
+
+	t.Run("Permanent failure is not retried", func(t *testing.T) {
+		vaultHelper := &mocks.FakeVaultHelper{}
+		vaultHelper.On("DeleteSecret", "secret").Return(&api.ResponseError{StatusCode: 403})
+
+		step := &DeleteVaultSecretStep{SecretName: "secret", Retry: policy}
+		assert.NotNil(t, step.Execute(newContext(vaultHelper)))
+		vaultHelper.AssertNumberOfCalls(t, "DeleteSecret", 1)
+	})
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TracerName = "github.com/Netcracker/qubership-nosqldb-operator-core"
+
+// Span attributes
+const (
+	NamespaceKey  = attribute.Key("k8s.namespace.name")
+	CRNameKey     = attribute.Key("qubership.cr.name")
+	StepNameKey   = attribute.Key("qubership.step.name")
+	StepPathKey   = attribute.Key("qubership.step.path")
+	StepResultKey = attribute.Key("qubership.step.result")
+	DeployTypeKey = attribute.Key("qubership.deploy.type")
+)
+
+// Tracer returns the tracer of the operator core. Spans are exported by the global tracer provider,
+// so nothing is exported until SetupOTLP or otel.SetTracerProvider is called.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records the error if any and ends the span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetupOTLP registers the global tracer provider which exports spans via OTLP over HTTP.
+// The exporter is configured with the standard OTEL_EXPORTER_OTLP_* environment variables.
+// The returned function flushes and stops the exporter and has to be called on operator shutdown.
+func SetupOTLP(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// SetupInMemory registers the global tracer provider which keeps finished spans in memory, it's intended for tests
+func SetupInMemory() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	return exporter
+}
@@ -36,6 +36,51 @@ type ServiceStatusCondition struct {
 	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
 }
 
+type RollbackStatus struct {
+	Status             string      `json:"status"`
+	Steps              []string    `json:"steps,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// StandbyStatus reports that the CR is left to the operator of another deployment version
+type StandbyStatus struct {
+	Standby            bool        `json:"standby"`
+	OperatorVersion    string      `json:"operatorVersion,omitempty"`
+	CRVersion          string      `json:"crVersion,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// DriftStatus reports the differences between the desired objects of the CR and the live ones
+type DriftStatus struct {
+	Drifted       bool            `json:"drifted"`
+	Objects       []DriftedObject `json:"objects,omitempty"`
+	Remediated    bool            `json:"remediated,omitempty"`
+	Message       string          `json:"message,omitempty"`
+	LastCheckTime metav1.Time     `json:"lastCheckTime"`
+}
+
+type DriftedObject struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Reason string   `json:"reason"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+type StepExecutionReport struct {
+	Path      string      `json:"path"`
+	Result    string      `json:"result"`
+	StartTime metav1.Time `json:"startTime"`
+	EndTime   metav1.Time `json:"endTime,omitempty"`
+	Duration  string      `json:"duration,omitempty"`
+	Message   string      `json:"message,omitempty"`
+}
+
+type ExecutionReport struct {
+	Steps []StepExecutionReport `json:"steps,omitempty"`
+}
+
 type VaultRegistration struct {
 	DockerImage            string                   `json:"dockerImage,omitempty"`
 	Enabled                bool                     `json:"enabled,omitempty"`
@@ -151,6 +196,125 @@ func (in *ServiceStatusCondition) DeepCopy() *ServiceStatusCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackStatus) DeepCopyInto(out *RollbackStatus) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackStatus.
+func (in *RollbackStatus) DeepCopy() *RollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StandbyStatus) DeepCopyInto(out *StandbyStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandbyStatus.
+func (in *StandbyStatus) DeepCopy() *StandbyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StandbyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]DriftedObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedObject) DeepCopyInto(out *DriftedObject) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedObject.
+func (in *DriftedObject) DeepCopy() *DriftedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepExecutionReport) DeepCopyInto(out *StepExecutionReport) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepExecutionReport.
+func (in *StepExecutionReport) DeepCopy() *StepExecutionReport {
+	if in == nil {
+		return nil
+	}
+	out := new(StepExecutionReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutionReport) DeepCopyInto(out *ExecutionReport) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]StepExecutionReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutionReport.
+func (in *ExecutionReport) DeepCopy() *ExecutionReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutionReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageRequirements) DeepCopyInto(out *StorageRequirements) {
 	*out = *in
@@ -1,24 +1,92 @@
 package vault
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"time"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/metrics"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/tracing"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	"github.com/docker/distribution/uuid"
 	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type VaultClientImpl struct {
 	VaultRegistration *types.VaultRegistration
 	client            *api.Client
+	// Ctx cancels requests to Vault, e.g. on operator shutdown. Background context is used if not set.
+	Ctx context.Context
 }
 
 func NewVaultClientImpl(vaultRegistration *types.VaultRegistration) VaultClientImpl {
 	return VaultClientImpl{VaultRegistration: vaultRegistration}
 }
 
+// WithContext returns a copy of the client which requests are cancelled with the context
+func (r VaultClientImpl) WithContext(ctx context.Context) VaultClientImpl {
+	r.Ctx = ctx
+	return r
+}
+
+func (r VaultClientImpl) goContext() context.Context {
+	if r.Ctx != nil {
+		return r.Ctx
+	}
+	return context.Background()
+}
+
+// request performs the logical request the same way as api.Logical does, but with the client context.
+// Not found response of read requests is returned as nil secret without error.
+func (r VaultClientImpl) request(client *api.Client, request *api.Request, read bool) (secret *api.Secret, err error) {
+	ctx, span := tracing.StartSpan(r.goContext(), "Vault "+request.Method,
+		attribute.String("vault.path", request.URL.Path))
+	start := time.Now()
+	defer func() {
+		tracing.EndSpan(span, err)
+		metrics.ObserveExternalCall(metrics.SystemVault, request.Method, start, err)
+	}()
+
+	resp, err := client.RawRequestWithContext(ctx, request)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if resp != nil && resp.StatusCode == 404 {
+		notFoundSecret, parseErr := api.ParseSecret(resp.Body)
+		switch parseErr {
+		case nil:
+		case io.EOF:
+			return nil, nil
+		default:
+			return nil, err
+		}
+		if notFoundSecret != nil && (len(notFoundSecret.Warnings) > 0 || len(notFoundSecret.Data) > 0) {
+			return notFoundSecret, nil
+		}
+		if read {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return api.ParseSecret(resp.Body)
+}
+
+func (r VaultClientImpl) write(client *api.Client, path string, data map[string]interface{}) (*api.Secret, error) {
+	request := client.NewRequest("PUT", "/v1/"+path)
+	if err := request.SetJSONBody(data); err != nil {
+		return nil, err
+	}
+	return r.request(client, request, false)
+}
+
 func (r VaultClientImpl) GetToken() (string, error) {
 	jwtToken, err := ReadFromFile(constants.TokenFilePath)
 	options := map[string]interface{}{
@@ -33,7 +101,7 @@ func (r VaultClientImpl) GetToken() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	clientToken, err := client.Logical().Write(loginPath, options)
+	clientToken, err := r.write(client, loginPath, options)
 	if err != nil {
 		return "", err
 	}
@@ -66,7 +134,7 @@ func (r *VaultClientImpl) refreshClient() error {
 
 func (r VaultClientImpl) VaultRead(path string) (map[string]interface{}, error) {
 	r.refreshClient()
-	secret, err := r.client.Logical().Read(path)
+	secret, err := r.request(r.client, r.client.NewRequest("GET", "/v1/"+path), true)
 	if err != nil {
 		return nil, err
 	}
@@ -81,19 +149,35 @@ func (r VaultClientImpl) VaultWrite(path string, secret map[string]interface{})
 	if err != nil {
 		return err
 	}
-	_, err = r.client.Logical().Write(path, secret)
+	_, err = r.write(r.client, path, secret)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// VaultDelete removes the path, absent path is not an error
+func (r VaultClientImpl) VaultDelete(path string) error {
+	err := r.refreshClient()
+	if err != nil {
+		return err
+	}
+	_, err = r.request(r.client, r.client.NewRequest("DELETE", "/v1/"+path), false)
+	var responseErr *api.ResponseError
+	if errors.As(err, &responseErr) && responseErr.StatusCode == 404 {
+		return nil
+	}
+	return err
+}
+
 func (r VaultClientImpl) VaultList(path string) (*api.Secret, error) {
 	err := r.refreshClient()
 	if err != nil {
 		return nil, err
 	}
-	secret, err := r.client.Logical().List(path)
+	request := r.client.NewRequest("GET", "/v1/"+path)
+	request.Params.Set("list", "true")
+	secret, err := r.request(r.client, request, true)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +211,7 @@ func (r VaultClientImpl) VaultCreatePasswordPolicy(policyName string, policy str
 	if err != nil {
 		return err
 	}
-	resp, respErr := r.client.RawRequest(request)
+	resp, respErr := r.client.RawRequestWithContext(r.goContext(), request)
 	if respErr != nil {
 		return respErr
 	}
@@ -22,6 +22,9 @@ type VaultHelper interface {
 	IsVaultURL(path string) bool
 	GetEnvTemplateForVault(envName string, secretName string) v1.EnvVar
 	ResolvePassword(passAddress string) (string, error)
+	DeleteSecret(secretName string) error
+	DeleteDatabaseConfig(configName string) error
+	DeleteStaticRole(rolePath string) error
 }
 
 type VaulterHelperImpl struct {
@@ -78,6 +81,14 @@ func (v VaulterHelperImpl) CreateStaticRole(rolePath string, roleSettings map[st
 	return v.VaultClient.VaultWrite(rolePath, roleSettings)
 }
 
+func (v VaulterHelperImpl) DeleteDatabaseConfig(configName string) error {
+	return v.VaultClient.VaultDelete("/database/config/" + configName)
+}
+
+func (v VaulterHelperImpl) DeleteStaticRole(rolePath string) error {
+	return v.VaultClient.VaultDelete(rolePath)
+}
+
 func (v VaulterHelperImpl) GetStaticRoleCredentials(roleName string) (map[string]interface{}, error) {
 	return v.VaultClient.VaultRead(fmt.Sprintf("database/static-creds/%s", roleName))
 }
@@ -114,6 +125,10 @@ func (v VaulterHelperImpl) CheckSecretExists(secretName string) (bool, map[strin
 	return secret != nil && len(secret) > 0, secret, nil
 }
 
+func (v VaulterHelperImpl) DeleteSecret(secretName string) error {
+	return v.VaultClient.VaultDelete(v.VaultClient.VaultRegistration.Path + "/" + secretName)
+}
+
 // TODO envvar
 func (v VaulterHelperImpl) GetEnvTemplateForVault(envName string, secretName string) v1.EnvVar {
 	return utils.GetEnvTemplateForVault(envName, secretName, constants.Password, v.VaultClient.VaultRegistration.Path)
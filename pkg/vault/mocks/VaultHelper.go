@@ -85,6 +85,60 @@ func (_m *FakeVaultHelper) CreateStaticRole(rolePath string, roleSettings map[st
 	return r0
 }
 
+// DeleteDatabaseConfig provides a mock function with given fields: configName
+func (_m *FakeVaultHelper) DeleteDatabaseConfig(configName string) error {
+	ret := _m.Called(configName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDatabaseConfig")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(configName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSecret provides a mock function with given fields: secretName
+func (_m *FakeVaultHelper) DeleteSecret(secretName string) error {
+	ret := _m.Called(secretName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSecret")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(secretName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteStaticRole provides a mock function with given fields: rolePath
+func (_m *FakeVaultHelper) DeleteStaticRole(rolePath string) error {
+	ret := _m.Called(rolePath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteStaticRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(rolePath)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GeneratePassword provides a mock function with given fields: policy
 func (_m *FakeVaultHelper) GeneratePassword(policy string) (string, error) {
 	ret := _m.Called(policy)
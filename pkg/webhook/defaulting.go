@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultingFunc sets operator specific defaults of the CR in place
+type DefaultingFunc func(ctx context.Context, object client.Object) error
+
+// DefaultingHandler patches the incoming CR with the defaults set by Default
+type DefaultingHandler struct {
+	Scheme    *runtime.Scheme
+	NewObject func() client.Object
+	Default   DefaultingFunc
+}
+
+var _ admission.Handler = &DefaultingHandler{}
+
+func NewDefaultingHandler(scheme *runtime.Scheme, newObject func() client.Object, defaultFunc DefaultingFunc) *DefaultingHandler {
+	return &DefaultingHandler{
+		Scheme:    scheme,
+		NewObject: newObject,
+		Default:   defaultFunc,
+	}
+}
+
+func (h *DefaultingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete || h.Default == nil {
+		return admission.Allowed("")
+	}
+
+	object := h.NewObject()
+	if err := admission.NewDecoder(h.Scheme).Decode(req, object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := callRecovered(func() error { return h.Default(ctx, object) }); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	defaulted, err := json.Marshal(object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ValidatingHandler admits the CR only if the Validate phase of the pipeline built for it succeeds.
+// The pipeline is never executed, but Build of the builder and Validate of the steps are called on every create
+// and on every update which changes more than the metadata of the CR, so they must not have side effects.
+type ValidatingHandler struct {
+	// Builder is the same builder which is used by ReconcileCommonService
+	Builder    core.ExecutableBuilder
+	Scheme     *runtime.Scheme
+	Client     client.Client
+	KubeConfig *rest.Config
+	// NewObject returns an empty CR the request is decoded to
+	NewObject func() client.Object
+	// Logger is passed to the builder, GetLogger is used if not set
+	Logger *zap.Logger
+}
+
+var _ admission.Handler = &ValidatingHandler{}
+
+func NewValidatingHandler(scheme *runtime.Scheme, kubeClient client.Client, newObject func() client.Object, builder core.ExecutableBuilder) *ValidatingHandler {
+	return &ValidatingHandler{
+		Builder:   builder,
+		Scheme:    scheme,
+		Client:    kubeClient,
+		NewObject: newObject,
+	}
+}
+
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	decoder := admission.NewDecoder(h.Scheme)
+	object := h.NewObject()
+	if err := decoder.Decode(req, object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// The finalizer and annotation updates of the operator itself don't need the pipeline to be built
+	if req.Operation == admissionv1.Update {
+		oldObject := h.NewObject()
+		if err := decoder.DecodeRaw(req.OldObject, oldObject); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		metadataOnly, err := isMetadataOnlyChange(oldObject, object)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if metadataOnly {
+			return admission.Allowed("")
+		}
+	}
+
+	err := h.validate(ctx, req, object)
+	if err == nil {
+		return admission.Allowed("")
+	}
+	return deniedResponse(err)
+}
+
+// validate builds the pipeline for the object and runs Validate of its root step
+func (h *ValidatingHandler) validate(ctx context.Context, req admission.Request, object client.Object) error {
+	logger := h.Logger
+	if logger == nil {
+		logger = core.GetLogger(false)
+	}
+	executionCtx := core.NewInitExecutionContext(map[string]interface{}{
+		constants.ContextSpec:       object,
+		constants.ContextSchema:     h.Scheme,
+		constants.ContextRequest:    reconcile.Request{NamespacedName: types.NamespacedName{Namespace: req.Namespace, Name: req.Name}},
+		constants.ContextClient:     h.Client,
+		constants.ContextKubeClient: h.KubeConfig,
+		constants.ContextLogger:     logger,
+		constants.ContextDryRun:     true,
+		constants.ContextGoContext:  ctx,
+	})
+
+	var root core.Executable
+	buildErr := callRecovered(func() error {
+		root = h.Builder.Build(executionCtx)
+		return nil
+	})
+	if buildErr != nil {
+		return &core.ExecutionError{Msg: fmt.Sprintf("Failed to build pipeline: %v", buildErr), Err: buildErr}
+	}
+	if root == nil {
+		return nil
+	}
+	return callRecovered(func() error { return root.Validate(executionCtx) })
+}
+
+// isMetadataOnlyChange reports whether the objects differ only in their metadata and status
+func isMetadataOnlyChange(oldObject client.Object, object client.Object) (bool, error) {
+	oldContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(oldObject)
+	if err != nil {
+		return false, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return false, err
+	}
+	for _, field := range []string{"metadata", "status"} {
+		delete(oldContent, field)
+		delete(content, field)
+	}
+	return equality.Semantic.DeepEqual(oldContent, content), nil
+}
+
+// deniedResponse lists the field errors as causes of the rejection
+func deniedResponse(err error) admission.Response {
+	response := admission.Denied(err.Error())
+	response.Result.Code = http.StatusUnprocessableEntity
+	response.Result.Reason = metav1.StatusReasonInvalid
+
+	var validationErr *core.ValidationError
+	var fieldErr *core.FieldError
+	var fieldErrs []*core.FieldError
+	if errors.As(err, &validationErr) {
+		fieldErrs = validationErr.Errors
+	} else if errors.As(err, &fieldErr) {
+		fieldErrs = []*core.FieldError{fieldErr}
+	}
+	if len(fieldErrs) > 0 {
+		details := &metav1.StatusDetails{}
+		for _, fieldErr := range fieldErrs {
+			details.Causes = append(details.Causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Field:   fieldErr.Field,
+				Message: fieldErr.Message,
+			})
+		}
+		response.Result.Details = details
+	}
+	return response
+}
+
+func callRecovered(f func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = core.PanicToError(recovered)
+		}
+	}()
+	return f()
+}
@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Paths the handlers are registered on by Register
+const (
+	DefaultValidatingPath = "/validate"
+	DefaultDefaultingPath = "/mutate"
+)
+
+// Register adds the handlers to the webhook server of the manager, nil handlers are skipped
+func Register(server webhook.Server, validating *ValidatingHandler, defaulting *DefaultingHandler) {
+	if validating != nil {
+		server.Register(DefaultValidatingPath, &admission.Webhook{Handler: validating})
+	}
+	if defaulting != nil {
+		server.Register(DefaultDefaultingPath, &admission.Webhook{Handler: defaulting})
+	}
+}
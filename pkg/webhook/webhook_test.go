@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type validatingStep struct {
+	core.DefaultExecutable
+}
+
+func (r *validatingStep) Validate(ctx core.ExecutionContext) error {
+	spec := core.SpecKey.MustGet(ctx).(*v1.ConfigMap)
+	validationErr := &core.ValidationError{}
+	if spec.Data["size"] == "" {
+		validationErr.Add("", core.NewFieldError("data.size", "should be set"))
+	}
+	if spec.Data["replicas"] == "" {
+		validationErr.Add("", core.NewFieldError("data.replicas", "should be set"))
+	}
+	return validationErr.ErrorOrNil()
+}
+
+func (r *validatingStep) Execute(ctx core.ExecutionContext) error {
+	panic("steps must not be executed by the webhook")
+}
+
+type testBuilder struct{}
+
+func (r *testBuilder) Build(ctx core.ExecutionContext) core.Executable {
+	compound := &core.DefaultCompound{}
+	compound.AddStep(&validatingStep{})
+	return compound
+}
+
+func admissionRequest(t *testing.T, object runtime.Object) admission.Request {
+	raw, err := json.Marshal(object)
+	assert.Nil(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "ns",
+		Name:      "cr",
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func TestValidatingHandler(t *testing.T) {
+	handler := NewValidatingHandler(scheme.Scheme, nil, func() client.Object { return &v1.ConfigMap{} }, &testBuilder{})
+	handler.Logger = zap.NewNop()
+
+	response := handler.Handle(context.Background(), admissionRequest(t, &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns"},
+	}))
+	assert.False(t, response.Allowed)
+	assert.Equal(t, metav1.StatusReasonInvalid, response.Result.Reason)
+	assert.Len(t, response.Result.Details.Causes, 2)
+	assert.Equal(t, "data.size", response.Result.Details.Causes[0].Field)
+
+	response = handler.Handle(context.Background(), admissionRequest(t, &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns"},
+		Data:       map[string]string{"size": "1Gi", "replicas": "3"},
+	}))
+	assert.True(t, response.Allowed)
+}
+
+// countingBuilder counts the pipelines built by the webhook
+type countingBuilder struct {
+	testBuilder
+	builds int
+}
+
+func (r *countingBuilder) Build(ctx core.ExecutionContext) core.Executable {
+	r.builds++
+	return r.testBuilder.Build(ctx)
+}
+
+func TestValidatingHandlerSkipsMetadataUpdates(t *testing.T) {
+	builder := &countingBuilder{}
+	handler := NewValidatingHandler(scheme.Scheme, nil, func() client.Object { return &v1.ConfigMap{} }, builder)
+	handler.Logger = zap.NewNop()
+	oldObject := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns"},
+		Data:       map[string]string{"size": "1Gi"},
+	}
+	updateRequest := func(object *v1.ConfigMap) admission.Request {
+		req := admissionRequest(t, object)
+		req.Operation = admissionv1.Update
+		req.OldObject = admissionRequest(t, oldObject).Object
+		return req
+	}
+
+	// The operator adds the finalizer to the CR which would not pass the validation
+	object := oldObject.DeepCopy()
+	object.Finalizers = []string{"netcracker.com/nosqldb-operator-cleanup"}
+	object.Annotations = map[string]string{"netcracker.com/force-reconcile": "now"}
+	assert.True(t, handler.Handle(context.Background(), updateRequest(object)).Allowed)
+	assert.Equal(t, 0, builder.builds)
+
+	object.Data = map[string]string{"size": "2Gi"}
+	assert.False(t, handler.Handle(context.Background(), updateRequest(object)).Allowed)
+	assert.Equal(t, 1, builder.builds)
+}
+
+func TestDefaultingHandler(t *testing.T) {
+	handler := NewDefaultingHandler(scheme.Scheme, func() client.Object { return &v1.ConfigMap{} },
+		func(ctx context.Context, object client.Object) error {
+			configMap := object.(*v1.ConfigMap)
+			if configMap.Data == nil {
+				configMap.Data = map[string]string{}
+			}
+			if configMap.Data["replicas"] == "" {
+				configMap.Data["replicas"] = "3"
+			}
+			return nil
+		})
+
+	response := handler.Handle(context.Background(), admissionRequest(t, &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns"},
+		Data:       map[string]string{"size": "1Gi"},
+	}))
+	assert.True(t, response.Allowed)
+	assert.Len(t, response.Patches, 1)
+	assert.Equal(t, "/data/replicas", response.Patches[0].Path)
+}